@@ -0,0 +1,258 @@
+package css
+
+import (
+	"slices"
+	"strings"
+)
+
+// systemColors are system colors of CSS Color Level 4, including deprecated
+// ones.
+var systemColors = []string{
+	"accentcolor", "accentcolortext", "activetext", "buttonborder",
+	"buttonface", "buttontext", "canvas", "canvastext", "field", "fieldtext",
+	"graytext", "highlight", "highlighttext", "linktext", "mark", "marktext",
+	"selecteditem", "selecteditemtext", "visitedtext",
+	// deprecated
+	"activeborder", "activecaption", "appworkspace", "background",
+	"buttonhighlight", "buttonshadow", "captiontext", "inactiveborder",
+	"inactivecaption", "inactivecaptiontext", "infobackground", "infotext",
+	"menu", "menutext", "scrollbar", "threeddarkshadow", "threedface",
+	"threedhighlight", "threedlightshadow", "threedshadow", "window",
+	"windowframe", "windowtext",
+}
+
+// rectangularColorSpaces are color spaces of color() and color-mix().
+var rectangularColorSpaces = []string{
+	"srgb", "srgb-linear", "display-p3", "a98-rgb", "prophoto-rgb", "rec2020",
+	"xyz", "xyz-d50", "xyz-d65",
+}
+
+// polarColorSpaces are color spaces of color-mix() with hue.
+var polarColorSpaces = []string{"hsl", "hwb", "lch", "oklch"}
+
+// hueInterpolations are hue interpolation methods of color-mix().
+var hueInterpolations = []string{"shorter", "longer", "increasing", "decreasing"}
+
+// colorComponent is a type of a component of color functions.
+type colorComponent int
+
+const (
+	// a number or a percentage
+	componentNumber colorComponent = iota
+	// a number or an angle
+	componentHue
+)
+
+// colorFunctions are color functions with types of their components.
+var colorFunctions = map[string][3]colorComponent{
+	"rgb":   {componentNumber, componentNumber, componentNumber},
+	"rgba":  {componentNumber, componentNumber, componentNumber},
+	"hsl":   {componentHue, componentNumber, componentNumber},
+	"hsla":  {componentHue, componentNumber, componentNumber},
+	"hwb":   {componentHue, componentNumber, componentNumber},
+	"lab":   {componentNumber, componentNumber, componentNumber},
+	"lch":   {componentNumber, componentNumber, componentHue},
+	"oklab": {componentNumber, componentNumber, componentNumber},
+	"oklch": {componentNumber, componentNumber, componentHue},
+}
+
+// legacyColorFunctions are color functions, which accept comma separated
+// components too.
+var legacyColorFunctions = []string{"rgb", "rgba", "hsl", "hsla"}
+
+// isColor returns true if value is a color of CSS Color Level 4 or 5, like
+// "red", "#fff", "currentcolor", "rgb(255 0 0 / 50%)", "oklch(70% 0.1 200)" or
+// "color-mix(in srgb, red 30%, blue)".
+func isColor(value string) bool {
+	switch {
+	case value == "transparent" || value == "currentcolor":
+		return true
+	case slices.Contains(colorValues, value):
+		return value != "initial" && value != "inherit"
+	case slices.Contains(systemColors, value):
+		return true
+	case HexRGB.MatchString(value):
+		return true
+	case !strings.HasSuffix(value, ")"):
+		return false
+	}
+
+	name, args, ok := strings.Cut(value[:len(value)-1], "(")
+	if !ok {
+		return false
+	}
+
+	switch name {
+	case "color":
+		return isColorFunction(args)
+	case "color-mix":
+		return isColorMix(args)
+	}
+
+	components, ok := colorFunctions[name]
+	if !ok {
+		return false
+	} else if slices.Contains(legacyColorFunctions, name) &&
+		strings.Contains(args, ",") {
+		return isLegacyColor(components, splitTopLevel(args, ","))
+	}
+
+	channels, alpha, ok := splitAlpha(args)
+	if !ok || len(channels) != len(components) {
+		return false
+	}
+	for i, c := range channels {
+		if !isColorComponent(c, components[i], true) {
+			return false
+		}
+	}
+	return alpha == "" || isColorComponent(alpha, componentNumber, true)
+}
+
+// isLegacyColor returns true if args are comma separated components of rgb() or
+// hsl(), which can't be none. Components of rgb() must be all numbers or all
+// percentages.
+func isLegacyColor(components [3]colorComponent, args []string) bool {
+	if len(args) != 3 && len(args) != 4 {
+		return false
+	}
+
+	var percents int
+	for i, arg := range args {
+		arg = strings.TrimSpace(arg)
+		component := componentNumber
+		if i < len(components) {
+			component = components[i]
+		}
+		if !isColorComponent(arg, component, false) {
+			return false
+		} else if i < len(components) && strings.HasSuffix(arg, "%") {
+			percents++
+		}
+	}
+
+	if components[0] == componentHue {
+		return true
+	}
+	return percents == 0 || percents == len(components)
+}
+
+// isColorFunction returns true if args are arguments of color(), like
+// "display-p3 1 0.5 0 / 50%".
+func isColorFunction(args string) bool {
+	channels, alpha, ok := splitAlpha(args)
+	if !ok || len(channels) != 4 ||
+		!slices.Contains(rectangularColorSpaces, channels[0]) {
+		return false
+	}
+
+	for _, c := range channels[1:] {
+		if !isColorComponent(c, componentNumber, true) {
+			return false
+		}
+	}
+	return alpha == "" || isColorComponent(alpha, componentNumber, true)
+}
+
+// isColorMix returns true if args are arguments of color-mix(), like
+// "in oklch longer hue, red 30%, blue".
+func isColorMix(args string) bool {
+	split := splitTopLevel(args, ",")
+	if len(split) != 3 {
+		return false
+	}
+
+	if !isColorInterpolation(strings.Fields(split[0])) {
+		return false
+	}
+
+	for _, arg := range split[1:] {
+		fields := splitFields(arg)
+		switch len(fields) {
+		case 1:
+			if !isColor(fields[0]) {
+				return false
+			}
+		case 2:
+			color, percent := fields[0], fields[1]
+			if !isColor(color) {
+				color, percent = percent, color
+			}
+			if !isColor(color) || !isPercentage(percent) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isColorInterpolation returns true if method is a color interpolation method,
+// like "in srgb" or "in oklch longer hue".
+func isColorInterpolation(method []string) bool {
+	switch {
+	case len(method) < 2 || method[0] != "in":
+		return false
+	case len(method) == 2:
+		return slices.Contains(rectangularColorSpaces, method[1]) ||
+			slices.Contains(polarColorSpaces, method[1]) ||
+			method[1] == "lab" || method[1] == "oklab"
+	case len(method) == 4:
+		return slices.Contains(polarColorSpaces, method[1]) &&
+			slices.Contains(hueInterpolations, method[2]) && method[3] == "hue"
+	}
+	return false
+}
+
+// splitAlpha returns space separated channels of a color function and its
+// alpha after "/", if any.
+func splitAlpha(args string) (channels []string, alpha string, ok bool) {
+	split := splitTopLevel(args, "/")
+	switch len(split) {
+	case 2:
+		if alpha = strings.TrimSpace(split[1]); alpha == "" {
+			return nil, "", false
+		}
+	case 1:
+	default:
+		return nil, "", false
+	}
+
+	return splitFields(split[0]), alpha, true
+}
+
+// isColorComponent returns true if value is a component of type component.
+// Modern syntax allows none.
+func isColorComponent(value string, component colorComponent, modern bool,
+) bool {
+	if value == "none" {
+		return modern
+	}
+
+	m := numberUnit.FindStringSubmatch(value)
+	if m == nil {
+		if component == componentHue {
+			return isMath(value, mathNumber, mathAngle)
+		}
+		return isMath(value, mathNumber, mathPercent)
+	}
+
+	switch unit := m[2]; {
+	case unit == "":
+		return true
+	case unit == "%":
+		return component == componentNumber
+	case component == componentHue:
+		return mathUnits[unit] == mathAngle
+	}
+	return false
+}
+
+// isPercentage returns true if value is a percentage.
+func isPercentage(value string) bool {
+	if m := numberUnit.FindStringSubmatch(value); m != nil {
+		return m[2] == "%"
+	}
+	return isMath(value, mathPercent)
+}
@@ -0,0 +1,90 @@
+package css
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorHandler(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"red", true},
+		{"lightsteelblue", true},
+		{"lightsteeelblue", false},
+		{"transparent", true},
+		{"currentcolor", true},
+		{"canvastext", true},
+		{"inherit", true},
+		{"red, blue", false},
+		{"#fff", true},
+		{"#ffff", true},
+		{"#ffffff80", true},
+		{"#fffff", false},
+		{"rgb(255, 0, 0)", true},
+		{"rgba(255, 0, 0, 0.5)", true},
+		{"rgb(100%, 0%, 0%)", true},
+		{"rgb(100%, 0, 0)", false},
+		{"rgb(255, 0)", false},
+		{"rgb(255 0 0)", true},
+		{"rgb(255 0 0 / 50%)", true},
+		{"rgb(255 0 0 / .5)", true},
+		{"rgb(255 none 0)", true},
+		{"rgb(255, none, 0)", false},
+		{"rgb(255 0 0 /)", false},
+		{"rgb(255 0 0 0)", false},
+		{"rgb(255px 0 0)", false},
+		{"hsl(120, 50%, 50%)", true},
+		{"hsla(120deg, 50%, 50%, 0.3)", true},
+		{"hsl(120deg 50% 50%)", true},
+		{"hsl(0.5turn 50% 50% / 1)", true},
+		{"hsl(120px 50% 50%)", false},
+		{"hwb(194 0% 0%)", true},
+		{"hwb(194 0% 0% / .5)", true},
+		{"lab(29.2345% 39.3825 20.0664)", true},
+		{"lch(52.2345% 72.2 56.2 / 50%)", true},
+		{"lch(52% 72 56%)", false},
+		{"oklab(40.1% 0.1143 0.045)", true},
+		{"oklch(70% 0.1 200)", true},
+		{"oklch(calc(50% + 10%) 0.1 calc(10deg * 2))", true},
+		{"oklch(calc(1px) 0.1 200)", false},
+		{"color(display-p3 1 0.5 0)", true},
+		{"color(srgb 100% 50% 0 / 0.5)", true},
+		{"color(foo 1 0.5 0)", false},
+		{"color(display-p3 1 0.5)", false},
+		{"color-mix(in srgb, red 30%, blue)", true},
+		{"color-mix(in oklch longer hue, 30% red, rgb(0 0 255))", true},
+		{"color-mix(in srgb, red, color-mix(in lab, blue, green 10%))", true},
+		{"color-mix(in srgb longer hue, red, blue)", false},
+		{"color-mix(srgb, red, blue)", false},
+		{"color-mix(in srgb, red 1px, blue)", false},
+		{"color-mix(in srgb, red)", false},
+		{"url(x)", false},
+		{"rgb(255 0 0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ColorHandler(tt.value))
+		})
+	}
+
+	assert.True(t, CaretColorHandler("auto"))
+	assert.True(t, CaretColorHandler("oklch(70% 0.1 200)"))
+	assert.False(t, ColorHandler("auto"))
+}
+
+func TestColorHandler_properties(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("border", "background-color", "outline-color").Globally()
+
+	for _, style := range []string{
+		"border: 1px solid rgb(255 0 0 / 50%)",
+		"background-color: color-mix(in srgb, red 30%, blue)",
+		"outline-color: currentcolor",
+	} {
+		assert.Equal(t, style, p.Sanitize("div", style))
+	}
+}
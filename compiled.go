@@ -0,0 +1,153 @@
+package css
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// maxCachedElements limits number of elements, which CompiledPolicy caches
+// resolved style policies of, besides elements with specific style policies.
+const maxCachedElements = 1024
+
+// CompiledPolicy is an immutable snapshot of a Policy, created by
+// Policy.Compile. Style policies are resolved per element once, so Sanitize
+// does less work than Policy.Sanitize.
+//
+// CompiledPolicy is safe for concurrent use by multiple goroutines. Calls of
+// AllowStyles on the source Policy don't affect it.
+type CompiledPolicy struct {
+	p        *Policy
+	elements map[string]elementRules
+
+	// cache of resolved style policies of other elements, like elements matched
+	// by regexp only
+	mu    sync.RWMutex
+	cache map[string]elementRules
+}
+
+// elementMatcher binds style policies of Policy.elsMatchingAndStyles or
+// Policy.elsMatchingAndDenies to their regexp.
+type elementMatcher struct {
+	regexp *regexp.Regexp
+	styles map[string][]stylePolicy
+}
+
+// Compile returns an immutable snapshot of the policy, which is safe for
+// concurrent use. Compile it again after changing the policy.
+func (self *Policy) Compile() *CompiledPolicy {
+	p := self.Clone()
+	c := &CompiledPolicy{
+		p:        p,
+		elements: make(map[string]elementRules, len(p.elsAndStyles)),
+		cache:    make(map[string]elementRules),
+	}
+
+	for elementName, sps := range p.elsAndStyles {
+		if len(sps) > 0 {
+			c.elements[elementName] = c.resolveRules(elementName)
+		}
+	}
+	for elementName := range p.elsAndDenies {
+		if _, ok := c.elements[elementName]; !ok {
+			c.elements[elementName] = c.resolveRules(elementName)
+		}
+	}
+	return c
+}
+
+// HasPolicies returns true if this CompiledPolicy has any policy for given
+// elementName.
+func (self *CompiledPolicy) HasPolicies(elementName string) bool {
+	if len(self.p.globalStyles) > 0 {
+		return true
+	} else if len(self.p.elsAndStyles[elementName]) > 0 {
+		return true
+	}
+
+	for _, m := range self.p.matchers {
+		if m.regexp.MatchString(elementName) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sanitize returns the style attribute of elementName with all declarations,
+// which aren't allowed by the policy, removed. See Policy.Sanitize.
+func (self *CompiledPolicy) Sanitize(elementName, style string) string {
+	if !self.HasPolicies(elementName) {
+		return ""
+	}
+	return self.p.sanitize(self.elementRules(elementName), style, nil)
+}
+
+// SanitizeWithReport works like Sanitize, but also returns every declaration
+// removed from the style attribute, with the reason why it was removed. See
+// Policy.SanitizeWithReport.
+func (self *CompiledPolicy) SanitizeWithReport(elementName, style string,
+) (string, []Rejection) {
+	var r report
+	clean := self.p.sanitize(self.elementRules(elementName), style, &r)
+	return clean, r
+}
+
+// elementRules returns style policies of elementName, resolving them once.
+func (self *CompiledPolicy) elementRules(elementName string) elementRules {
+	if rules, ok := self.elements[elementName]; ok {
+		return rules
+	}
+
+	self.mu.RLock()
+	rules, ok := self.cache[elementName]
+	self.mu.RUnlock()
+	if ok {
+		return rules
+	}
+
+	rules = self.resolveRules(elementName)
+	self.mu.Lock()
+	if len(self.cache) < maxCachedElements {
+		self.cache[elementName] = rules
+	}
+	self.mu.Unlock()
+	return rules
+}
+
+func (self *CompiledPolicy) resolveRules(elementName string) elementRules {
+	return resolveRules(elementName,
+		self.p.elsAndStyles[elementName], self.p.matchers,
+		self.p.elsAndDenies[elementName], self.p.denyMatchers)
+}
+
+// sortedMatchers returns matching style policies ordered by source of their
+// regexp, so they always merge in the same order.
+func sortedMatchers(m map[*regexp.Regexp]map[string][]stylePolicy,
+) []elementMatcher {
+	matchers := make([]elementMatcher, 0, len(m))
+	for regex, styles := range m {
+		if len(styles) > 0 {
+			matchers = append(matchers, elementMatcher{regexp: regex, styles: styles})
+		}
+	}
+
+	slices.SortStableFunc(matchers, func(a, b elementMatcher) int {
+		return strings.Compare(a.regexp.String(), b.regexp.String())
+	})
+	return matchers
+}
+
+// mergeMatching merges style policies of all matchers matching elementName.
+func mergeMatching(matchers []elementMatcher, elementName string,
+) map[string][]stylePolicy {
+	sps := map[string][]stylePolicy{}
+	for _, m := range matchers {
+		if m.regexp.MatchString(elementName) {
+			for k, v := range m.styles {
+				sps[k] = append(sps[k], v...)
+			}
+		}
+	}
+	return sps
+}
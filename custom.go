@@ -0,0 +1,204 @@
+package css
+
+import (
+	"regexp"
+	"strings"
+)
+
+// customProperties is the name of style policies of all custom properties.
+// Every style policy under this name has a regexp of property names it
+// applies to.
+const customProperties = "--"
+
+// styleKey returns the name of style policies of property.
+func styleKey(property string) string {
+	if strings.HasPrefix(property, customProperties) {
+		return customProperties
+	}
+	return property
+}
+
+// AllowCustomProperties allows custom properties with names matching regex,
+// like "--brand-color", and returns a builder for their value policy. Without
+// validators values are checked by CustomPropertyHandler, like
+//
+//	p.AllowCustomProperties(regexp.MustCompile(`^--brand-`)).Globally()
+//	p.AllowCustomProperties(regexp.MustCompile(`^--space-`)).
+//	  MatchingHandler(css.LengthHandler).Globally()
+//
+// Names of custom properties are matched in lowercase. Custom properties can
+// also be allowed by name, using AllowStyles("--brand-color").
+func (self *Policy) AllowCustomProperties(regex *regexp.Regexp) *PolicyBuilder {
+	b := NewPolicyBuilder(self, customProperties)
+	b.propertyRegexp = regex
+	return b
+}
+
+// AllowVariables allows var() references to custom properties with names, like
+// "--brand-color", in values of all properties, and returns the updated
+// policy. Without AllowVariables(...) var() isn't recognised.
+//
+// A var() of custom property, which is declared in the same style attribute,
+// is replaced by its value, otherwise by its fallback, like "red" in
+// "var(--brand-color, red)", and the value is validated by style policies of
+// its property. A var() without fallback is allowed only as the whole value,
+// like "var(--brand-color)", because its value is validated by style policies
+// of the custom property.
+//
+// A value of custom property, which isn't declared in the same style
+// attribute, is unknown, so a value with such var() is removed if the property
+// has deny rules, or style policies with MatchingEnum(...) or
+// MatchingRange(...).
+func (self *Policy) AllowVariables(names ...string) *Policy {
+	self.variables = lowerStrings(names)
+	return self
+}
+
+// variable is a value with var() resolved.
+type variable struct {
+	value string
+
+	// true if the value is a single var() without fallback, which isn't
+	// resolved
+	bare bool
+
+	// true if the value has var() of custom property, which isn't declared in
+	// the style attribute
+	unresolved bool
+
+	// false if the value references a variable, which isn't allowed, or can't
+	// be resolved
+	ok bool
+}
+
+// variables are values of custom properties declared in a style attribute,
+// with var() resolved.
+type variables map[string]variable
+
+func (self variables) lookup(name string) (variable, bool) {
+	v, ok := self[name]
+	return v, ok
+}
+
+// blockVariables returns variables with values of custom properties from
+// declared. A custom property, which references itself, directly or not, can't
+// be resolved.
+func (self *Policy) blockVariables(declared map[string]string) variables {
+	if self.variables == nil || len(declared) == 0 {
+		return nil
+	}
+
+	vars := make(variables, len(declared))
+	var lookup func(name string) (variable, bool)
+	lookup = func(name string) (variable, bool) {
+		value, ok := declared[name]
+		if !ok {
+			return variable{}, false
+		} else if v, ok := vars[name]; ok {
+			return v, true
+		}
+		vars[name] = variable{}
+		v := self.resolveVariables(value, lookup)
+		vars[name] = v
+		return v, true
+	}
+
+	for name := range declared {
+		lookup(name)
+	}
+	return vars
+}
+
+// resolveVariables returns value with every var() replaced by the value of its
+// custom property, returned by lookup, or by its fallback. It returns not ok
+// variable if value references a variable, which isn't allowed, or has var()
+// without fallback, which isn't the whole value.
+func (self *Policy) resolveVariables(value string,
+	lookup func(name string) (variable, bool),
+) variable {
+	if self.variables == nil {
+		return variable{value: value, ok: true}
+	}
+
+	var unresolved bool
+	for start := 0; ; {
+		i := strings.Index(value[start:], "var(")
+		if i < 0 {
+			return variable{value: value, unresolved: unresolved, ok: true}
+		}
+		i += start
+		if i > 0 && isNameChar(value[i-1]) {
+			start = i + len("var(")
+			continue
+		}
+
+		end := closingParen(value, i+len("var("))
+		if end < 0 {
+			return variable{}
+		}
+
+		name, fallback, _ := strings.Cut(value[i+len("var("):end], ",")
+		name = strings.TrimSpace(name)
+		if !strings.HasPrefix(name, customProperties) ||
+			!stringInSlice(name, self.variables) {
+			return variable{}
+		}
+
+		whole := strings.TrimSpace(value[:i]) == "" &&
+			strings.TrimSpace(value[end+1:]) == ""
+		v, declared := variable{}, false
+		if lookup != nil {
+			v, declared = lookup(name)
+		}
+		if !declared {
+			v = self.resolveVariables(strings.TrimSpace(fallback), lookup)
+			v.unresolved = true
+		}
+
+		switch {
+		case !v.ok:
+			return variable{}
+		case v.value == "" || v.bare:
+			return variable{bare: whole, unresolved: true, ok: whole}
+		}
+
+		n := i + len(v.value) + len(value) - end - 1
+		if exceeds(n, self.resolvedLengthLimit()) {
+			return variable{}
+		}
+		value = value[:i] + v.value + value[end+1:]
+		unresolved = unresolved || v.unresolved
+		start = i + len(v.value)
+	}
+}
+
+// maxResolvedLength is the maximum length of a value with var() resolved,
+// whatever limits of the policy are, because every var() of a custom property,
+// which references other custom properties, can double the length.
+const maxResolvedLength = 64 << 10
+
+// resolvedLengthLimit returns the maximum length of a value with var()
+// resolved: MaxValueLength of the policy, but not over maxResolvedLength.
+func (self *Policy) resolvedLengthLimit() int {
+	if limit := self.limits.MaxValueLength; limit > 0 {
+		return min(limit, maxResolvedLength)
+	}
+	return maxResolvedLength
+}
+
+// closingParen returns index of the parenthesis, which closes the one before
+// start, or -1.
+func closingParen(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
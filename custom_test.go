@@ -0,0 +1,249 @@
+package css
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowCustomProperties(t *testing.T) {
+	p := NewPolicy()
+	p.AllowCustomProperties(regexp.MustCompile(`^--brand-`)).Globally()
+	p.AllowCustomProperties(regexp.MustCompile(`^--space-`)).
+		MatchingRange(0, 64, "px").OnElements("div")
+	p.AllowStyles("--Accent").MatchingEnum("red", "blue").Globally()
+	require.NoError(t, p.Err())
+
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{in: "--brand-color: #fff", expected: "--brand-color: #fff"},
+		{
+			in:       "--brand-shadow: 0 1px 2px rgb(0, 0, 0)",
+			expected: "--brand-shadow: 0 1px 2px rgb(0, 0, 0)",
+		},
+		{in: "--brand-bg: url(https://example.com/a.png)"},
+		{in: "--brand-font: 'Comic Sans'"},
+		{in: "--space-s: 4px", expected: "--space-s: 4px"},
+		{in: "--space-s: red"},
+		{in: "--accent: blue", expected: "--accent: blue"},
+		{in: "--ACCENT: red", expected: "--ACCENT: red"},
+		{in: "--accent: green"},
+		{in: "--other: 1px"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.expected, p.Sanitize("div", tt.in))
+		})
+	}
+	assert.Empty(t, p.Sanitize("span", "--space-s: 4px"))
+
+	rules := p.AllowedStyles("span")
+	require.Len(t, rules, 2)
+	assert.Equal(t, "--", rules[0].Property)
+	assert.ElementsMatch(t, []string{`^--brand-`, `^--accent$`},
+		[]string{rules[0].PropertyPattern, rules[1].PropertyPattern})
+
+	e := p.Explain("div", "--space-s", "4px")
+	assert.Equal(t, "--space-s: 4px", e.Output)
+	require.NotEmpty(t, e.Steps)
+	assert.Equal(t, `^--space-`, e.Steps[0].Rule.PropertyPattern)
+
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	p2 := NewPolicy()
+	require.NoError(t, json.Unmarshal(b, p2))
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, p2.Sanitize("div", tt.in), tt.in)
+	}
+}
+
+func TestAllowVariables(t *testing.T) {
+	p := NewPolicy().AllowVariables("--brand-color", "--brand-bg", "--space")
+	p.AllowStyles("color", "margin", "border").Globally()
+	p.AllowCustomProperties(regexp.MustCompile(`^--brand-`)).Globally()
+	require.NoError(t, p.Err())
+
+	tests := []struct {
+		in       string
+		expected string
+		reason   RejectReason
+	}{
+		{in: "color: var(--brand-color)", expected: "color: var(--brand-color)"},
+		{
+			in:       "color: var( --Brand-Color , red )",
+			expected: "color: var( --Brand-Color , red )",
+		},
+		{in: "color: var(--brand-color, 10px)", reason: RejectHandler},
+		{
+			in:       "color: var(--brand-color, var(--brand-bg, red))",
+			expected: "color: var(--brand-color, var(--brand-bg, red))",
+		},
+		{
+			in:       "color: var(--brand-color, var(--brand-bg))",
+			expected: "color: var(--brand-color, var(--brand-bg))",
+		},
+		{in: "color: var(--other)", reason: RejectVariable},
+		{in: "color: var(--brand-color, var(--other, red))", reason: RejectVariable},
+		{
+			in:       "margin: var(--space, 1px) 2px",
+			expected: "margin: var(--space, 1px) 2px",
+		},
+		{in: "margin: var(--space) 2px", reason: RejectVariable},
+		{in: "margin: var(--space, 1px 2px", reason: RejectVariable},
+		{
+			in:       "border: 1px solid var(--brand-color, red)",
+			expected: "border: 1px solid var(--brand-color, red)",
+		},
+		{
+			in:       "--brand-bg: var(--brand-color, #000)",
+			expected: "--brand-bg: var(--brand-color, #000)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			clean, rejected := p.SanitizeWithReport("div", tt.in)
+			assert.Equal(t, tt.expected, clean)
+			if tt.reason != 0 {
+				require.Len(t, rejected, 1)
+				assert.Equal(t, tt.reason, rejected[0].Reason)
+			}
+		})
+	}
+
+	assert.Empty(t, NewPolicy().AllowStyles("color").Globally().
+		Sanitize("div", "color: var(--brand-color)"))
+
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	p2 := NewPolicy()
+	require.NoError(t, json.Unmarshal(b, p2))
+	assert.Equal(t, "color: var(--brand-color)",
+		p2.Sanitize("div", "color: var(--brand-color)"))
+}
+
+func TestAllowVariables_declared(t *testing.T) {
+	p := NewPolicy().AllowVariables("--p", "--size", "--a", "--b")
+	p.AllowStyles("position", "color").Globally()
+	p.DisallowStyles("position").MatchingEnum("fixed").Globally()
+	p.AllowStyles("font-size").MatchingRange(8, 48, "px").Globally()
+	p.AllowCustomProperties(regexp.MustCompile(`^--`)).Globally()
+	require.NoError(t, p.Err())
+
+	tests := []struct {
+		in       string
+		expected string
+		reasons  []RejectReason
+	}{
+		{
+			in:       "--p: fixed; position: var(--p)",
+			expected: "--p: fixed",
+			reasons:  []RejectReason{RejectDenied},
+		},
+		{
+			in:       "position: var(--p, static); --p: fixed",
+			expected: "--p: fixed",
+			reasons:  []RejectReason{RejectDenied},
+		},
+		{
+			in:       "--p: static; position: var(--p)",
+			expected: "--p: static; position: var(--p)",
+		},
+		{
+			in:       "--p: fixed; --p: static; position: var(--p)",
+			expected: "--p: fixed; --p: static; position: var(--p)",
+		},
+		{
+			in:       "--a: var(--b); --b: fixed; position: var(--a)",
+			expected: "--a: var(--b); --b: fixed",
+			reasons:  []RejectReason{RejectDenied},
+		},
+		{
+			in:       "--a: var(--b); --b: var(--a); position: var(--a)",
+			expected: "--a: var(--b); --b: var(--a)",
+			reasons:  []RejectReason{RejectVariable},
+		},
+		{in: "position: var(--p)", reasons: []RejectReason{RejectVariable}},
+		{
+			in:      "position: var(--p, static)",
+			reasons: []RejectReason{RejectVariable},
+		},
+		{
+			in:       "--size: 500px; font-size: var(--size)",
+			expected: "--size: 500px",
+			reasons:  []RejectReason{RejectRange},
+		},
+		{
+			in:       "--size: 12px; font-size: var(--size)",
+			expected: "--size: 12px; font-size: var(--size)",
+		},
+		{in: "font-size: var(--size)", reasons: []RejectReason{RejectVariable}},
+		{
+			in:      "font-size: var(--size, 12px)",
+			reasons: []RejectReason{RejectVariable},
+		},
+		{in: "color: var(--a)", expected: "color: var(--a)"},
+		{
+			in:       "--a: 10px; color: var(--a)",
+			expected: "--a: 10px",
+			reasons:  []RejectReason{RejectHandler},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			clean, rejected := p.SanitizeWithReport("div", tt.in)
+			assert.Equal(t, tt.expected, clean)
+			var reasons []RejectReason
+			for _, r := range rejected {
+				reasons = append(reasons, r.Reason)
+			}
+			assert.Equal(t, tt.reasons, reasons)
+		})
+	}
+}
+
+func TestAllowVariables_Merge(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("color").Globally()
+	other := NewPolicy().AllowVariables("--x")
+	p.Merge(other)
+	assert.Equal(t, "color: var(--x, red)", p.Sanitize("div", "color: var(--x, red)"))
+	assert.Empty(t, p.Sanitize("div", "color: var(--y, red)"))
+
+	p.Merge(NewPolicy().AllowVariables("--y"))
+	assert.Equal(t, "color: var(--x, red); color: var(--y, red)",
+		p.Sanitize("div", "color: var(--x, red); color: var(--y, red)"))
+
+	p = NewPolicy().Merge(NewPolicy().AllowVariables())
+	assert.NotNil(t, p.variables)
+	assert.Nil(t, NewPolicy().Merge(NewPolicy()).variables)
+}
+
+func TestAllowVariables_expansion(t *testing.T) {
+	const n = 40
+	names := make([]string, n)
+	decs := make([]string, n)
+	for i := range n {
+		names[i] = "--v" + strconv.Itoa(i)
+		decs[i] = fmt.Sprintf("--v%d: var(--v%d, 0) var(--v%d, 0)", i, i+1, i+1)
+	}
+	decs = append(decs, "margin: var(--v0)")
+
+	p := NewPolicy().Limits(Limits{}).AllowVariables(names...)
+	p.AllowStyles("margin").Globally()
+	p.AllowCustomProperties(regexp.MustCompile(`^--v`)).Globally()
+
+	// every custom property doubles the length of its value, which was
+	// exponential without limits
+	assert.NotContains(t, p.Sanitize("div", strings.Join(decs, "; ")), "margin")
+}
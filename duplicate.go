@@ -0,0 +1,120 @@
+package css
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DuplicateMode describes how repeated declarations of the same property are
+// emitted.
+type DuplicateMode int
+
+const (
+	// DuplicateFallbacks emits all accepted declarations in order, so earlier
+	// declarations are fallbacks for browsers, which don't support later ones,
+	// like
+	//
+	//	width: 100px; width: min(100px, 50vw)
+	//
+	// It's the default.
+	DuplicateFallbacks DuplicateMode = iota
+
+	// DuplicateOnce emits every accepted declaration once. Of declarations with
+	// the same property and value, only the last one is emitted, which is the
+	// one the browser applies.
+	DuplicateOnce
+
+	// DuplicateLast emits only the last accepted declaration of every property,
+	// or the last one with !important, if any, which is the one the browser
+	// applies.
+	DuplicateLast
+)
+
+var duplicateModeNames = map[DuplicateMode]string{
+	DuplicateFallbacks: "fallbacks",
+	DuplicateOnce:      "once",
+	DuplicateLast:      "last",
+}
+
+func (self DuplicateMode) String() string {
+	if s, ok := duplicateModeNames[self]; ok {
+		return s
+	}
+	return "DuplicateMode(" + strconv.Itoa(int(self)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (self DuplicateMode) MarshalText() ([]byte, error) {
+	if s, ok := duplicateModeNames[self]; ok {
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("css: unknown duplicate mode %d", int(self))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (self *DuplicateMode) UnmarshalText(text []byte) error {
+	for mode, s := range duplicateModeNames {
+		if s == string(text) {
+			*self = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("css: unknown duplicate mode %q", text)
+}
+
+// Duplicates sets how repeated declarations of the same property are emitted
+// and returns the updated policy.
+//
+// Every declaration is emitted at most once, regardless of the mode, even if
+// it's allowed by both element specific and global style policies.
+func (self *Policy) Duplicates(mode DuplicateMode) *Policy {
+	self.duplicates = mode
+	return self
+}
+
+// emitted is a declaration accepted by Policy.check.
+type emitted struct {
+	// lowercase property name as it's emitted, with vendor prefix
+	property string
+
+	// the declaration as it's emitted
+	output string
+
+	// true if the declaration is emitted with !important
+	important bool
+}
+
+// dedup returns emitted declarations according to the duplicate mode of the
+// policy.
+func (self *Policy) dedup(decs []emitted) []string {
+	clean := make([]string, 0, len(decs))
+	if self.duplicates == DuplicateFallbacks {
+		for i := range decs {
+			clean = append(clean, decs[i].output)
+		}
+		return clean
+	}
+
+	keys := make([]string, len(decs))
+	last := make(map[string]int, len(decs))
+	for i := range decs {
+		keys[i] = decs[i].property
+		if self.duplicates == DuplicateOnce {
+			keys[i] = strings.ToLower(decs[i].output)
+		}
+		// an earlier declaration with !important wins over later ones without
+		// it, like in the browser
+		if j, ok := last[keys[i]]; ok && decs[j].important && !decs[i].important {
+			continue
+		}
+		last[keys[i]] = i
+	}
+
+	for i := range decs {
+		if last[keys[i]] == i {
+			clean = append(clean, decs[i].output)
+		}
+	}
+	return clean
+}
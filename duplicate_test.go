@@ -0,0 +1,111 @@
+package css
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicates(t *testing.T) {
+	const style = "color: red; width: 1px; color: blue; Color: red; " +
+		"-webkit-transform: none; transform: none"
+
+	tests := []struct {
+		name      string
+		mode      DuplicateMode
+		important ImportantMode
+		in        string
+		expected  string
+	}{
+		{
+			name:     "fallbacks",
+			mode:     DuplicateFallbacks,
+			in:       style,
+			expected: "color: red; width: 1px; color: blue; Color: red; -webkit-transform: none; transform: none",
+		},
+		{
+			name:     "once",
+			mode:     DuplicateOnce,
+			in:       style,
+			expected: "width: 1px; color: blue; Color: red; -webkit-transform: none; transform: none",
+		},
+		{
+			name:     "last",
+			mode:     DuplicateLast,
+			in:       style,
+			expected: "width: 1px; Color: red; -webkit-transform: none; transform: none",
+		},
+		{
+			name:      "last important",
+			mode:      DuplicateLast,
+			important: ImportantPreserve,
+			in:        "color: red !important; width: 1px; color: blue; width: 2px !important; width: 3px",
+			expected:  "color: red !important; width: 2px !important",
+		},
+		{
+			name:      "last important stripped",
+			mode:      DuplicateLast,
+			important: ImportantStrip,
+			in:        "color: red !important; color: blue",
+			expected:  "color: blue",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPolicy().Duplicates(tt.mode).Important(tt.important)
+			p.AllowStyles("color", "transform").OnElements("div")
+			p.AllowStyles("color", "width", "transform").Globally()
+			require.NoError(t, p.Err())
+
+			assert.Equal(t, tt.expected, p.Sanitize("div", tt.in))
+			assert.Equal(t, tt.expected, p.Compile().Sanitize("div", tt.in))
+
+			b, err := json.Marshal(p)
+			require.NoError(t, err)
+			p2 := NewPolicy()
+			require.NoError(t, json.Unmarshal(b, p2))
+			assert.Equal(t, tt.expected, p2.Sanitize("div", tt.in))
+		})
+	}
+}
+
+func TestDuplicates_elementAndGlobal(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("color").OnElements("div")
+	p.AllowStyles("color").OnElementsMatching(regexp.MustCompile(`^d`))
+	p.AllowStyles("color").Globally()
+
+	assert.Equal(t, "color: red", p.Sanitize("div", "color: red"))
+	assert.Equal(t, "color: red", p.Sanitize("dd", "color: red"))
+
+	e := p.Explain("div", "color", "red")
+	assert.Equal(t, "color: red", e.Output)
+	assert.Len(t, e.Steps, 1)
+
+	// the element specific style policy rejects it, but global one accepts
+	p = NewPolicy()
+	p.AllowStyles("color").MatchingEnum("red").OnElements("div")
+	p.AllowStyles("color").Globally()
+	assert.Equal(t, "color: blue", p.Sanitize("div", "color: blue"))
+}
+
+func TestDuplicateMode_UnmarshalText(t *testing.T) {
+	var mode DuplicateMode
+	require.NoError(t, mode.UnmarshalText([]byte("last")))
+	assert.Equal(t, DuplicateLast, mode)
+	require.Error(t, mode.UnmarshalText([]byte("first")))
+	assert.Equal(t, "DuplicateMode(10)", DuplicateMode(10).String())
+}
+
+func TestDuplicates_Merge(t *testing.T) {
+	p := NewPolicy().Merge(NewPolicy().Duplicates(DuplicateLast))
+	assert.Equal(t, DuplicateLast, p.duplicates)
+
+	p = NewPolicy().Duplicates(DuplicateOnce).
+		Merge(NewPolicy().Duplicates(DuplicateLast))
+	assert.Equal(t, DuplicateOnce, p.duplicates)
+}
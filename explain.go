@@ -0,0 +1,153 @@
+package css
+
+import (
+	"regexp"
+	"slices"
+)
+
+// Explanation describes how the policy decides about a single declaration.
+type Explanation struct {
+	Element  string
+	Property string
+	Value    string
+
+	// Name is lowercase Property without vendor prefix, which style policies
+	// are looked up by.
+	Name string
+
+	// Prefix is the vendor prefix removed from Property.
+	Prefix string
+
+	// Normalized is lowercase Value with unicode escape sequences decoded, which
+	// validators check.
+	Normalized string
+
+	// Steps are all deny rules and style policies tried, in order.
+	Steps []ExplainStep
+
+	// Output is the declaration emitted by Sanitize. It's empty if the
+	// declaration is removed.
+	Output string
+
+	// Reason is why the declaration is removed.
+	Reason RejectReason
+}
+
+// ExplainStep describes a single deny rule or style policy tried by Explain.
+type ExplainStep struct {
+	Rule StyleRule
+
+	// Deny is true for deny rules.
+	Deny bool
+
+	// Matched is true if the style policy allowed the value, or the deny rule
+	// matched it.
+	Matched bool
+
+	// Validator is the validator of Rule, which decided. It's nil for deny rules
+	// without validators and for style policies with transformer only, or if
+	// the transformer rejected the value.
+	Validator *Validator
+
+	// Reason is why the style policy rejected the value.
+	Reason RejectReason
+}
+
+// Explain returns how the policy decides about a single declaration of
+// property with value on elementName, like
+//
+//	e := p.Explain("span", "color", "#f00ba")
+//
+// It's useful for debugging why a declaration is removed by Sanitize.
+func (self *Policy) Explain(elementName, property, value string) Explanation {
+	e := Explanation{Element: elementName, Property: property, Value: value}
+	d, ok := self.normalize(property, value)
+	e.Name, e.Prefix = d.name, d.prefix
+	if !ok {
+		e.Reason = RejectUnicode
+		return e
+	}
+	e.Normalized = d.normalized
+
+	key := styleKey(d.name)
+	deny, denyRules := self.scopedStyles(key, elementName,
+		self.elsAndDenies, self.elsMatchingAndDenies, self.globalDenies, true)
+	allow, allowRules := self.scopedStyles(key, elementName,
+		self.elsAndStyles, self.elsMatchingAndStyles, self.globalStyles, false)
+
+	emit, reason := self.check(&d, nil, deny, allow,
+		func(isDeny bool, group, index int, ok bool, reason RejectReason) {
+			rules, spl := allowRules, allow
+			if isDeny {
+				rules, spl = denyRules, deny
+			}
+			e.Steps = append(e.Steps,
+				self.explainStep(rules[group], &spl[group][index], isDeny, ok, reason))
+		})
+
+	e.Output = emit
+	if emit == "" {
+		e.Reason = reason
+	}
+	return e
+}
+
+// scopedStyles returns style policies of property, grouped by scope, in the
+// same order as Sanitize tries them, and rules of every group.
+func (self *Policy) scopedStyles(property, elementName string,
+	els map[string]map[string][]stylePolicy,
+	elsMatching map[*regexp.Regexp]map[string][]stylePolicy,
+	global map[string][]stylePolicy, deny bool,
+) ([][]stylePolicy, []StyleRule) {
+	var groups [][]stylePolicy
+	var rules []StyleRule
+	add := func(scope Scope, pattern string, spl []stylePolicy) {
+		if len(spl) > 0 {
+			groups = append(groups, spl)
+			rules = append(rules, StyleRule{
+				Property: property, Scope: scope, Pattern: pattern,
+			})
+		}
+	}
+
+	styles := els[elementName]
+	add(ScopeElement, "", styles[property])
+	if deny || len(styles) == 0 {
+		for _, m := range sortedMatchers(elsMatching) {
+			if m.regexp.MatchString(elementName) {
+				add(ScopeMatching, m.regexp.String(), m.styles[property])
+			}
+		}
+	}
+	add(ScopeGlobal, "", global[property])
+	return groups, rules
+}
+
+func (self *Policy) explainStep(rule StyleRule, sp *stylePolicy, deny,
+	ok bool, reason RejectReason,
+) ExplainStep {
+	rule.Validators = self.validators(sp)
+	rule.All, rule.Transform = sp.matchAll, sp.transform != nil
+	rule.Prefixes, rule.Important = slices.Clone(sp.prefixes), sp.important
+	rule.Units = slices.Clone(sp.units)
+	if sp.propertyRegexp != nil {
+		rule.PropertyPattern = sp.propertyRegexp.String()
+	}
+	step := ExplainStep{Rule: rule, Deny: deny, Matched: ok}
+	if !ok {
+		step.Reason = reason
+	}
+
+	for i := range rule.Validators {
+		v := &rule.Validators[i]
+		switch {
+		case reason == RejectHandler &&
+			(v.Kind == ValidatorHandler || v.Kind == ValidatorDefault),
+			reason == RejectEnum && v.Kind == ValidatorEnum,
+			reason == RejectRegexp && v.Kind == ValidatorRegexp,
+			reason == RejectRange && v.Kind == ValidatorRange:
+			step.Validator = v
+		}
+	}
+	return step
+}
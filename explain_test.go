@@ -0,0 +1,67 @@
+package css
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Explain(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("color").
+		Matching(regexp.MustCompile("(?i)^#([0-9a-f]{3,4}|[0-9a-f]{6}|[0-9a-f]{8})$")).
+		OnElements("span")
+	p.AllowStyles("color").Globally()
+	p.AllowStyles("transform").
+		OnElementsMatching(regexp.MustCompile(`^d`))
+	p.DisallowStyles("color").MatchingEnum("red").
+		OnElementsMatching(regexp.MustCompile(`^s`))
+
+	e := p.Explain("span", "COLOR", "#F00BA")
+	assert.Equal(t, "color", e.Name)
+	assert.Equal(t, "#f00ba", e.Normalized)
+	assert.Empty(t, e.Output)
+	assert.Equal(t, RejectHandler, e.Reason)
+	require.Len(t, e.Steps, 3)
+
+	assert.True(t, e.Steps[0].Deny)
+	assert.False(t, e.Steps[0].Matched)
+	assert.Equal(t, ScopeMatching, e.Steps[0].Rule.Scope)
+	assert.Equal(t, "^s", e.Steps[0].Rule.Pattern)
+	assert.Equal(t, &Validator{Kind: ValidatorEnum, Enum: []string{"red"}},
+		e.Steps[0].Validator)
+
+	assert.Equal(t, ScopeElement, e.Steps[1].Rule.Scope)
+	assert.Equal(t, RejectRegexp, e.Steps[1].Reason)
+	assert.Equal(t, ValidatorRegexp, e.Steps[1].Validator.Kind)
+
+	assert.Equal(t, ScopeGlobal, e.Steps[2].Rule.Scope)
+	assert.Equal(t, RejectHandler, e.Steps[2].Reason)
+	assert.Equal(t, &Validator{Kind: ValidatorDefault, Name: "color"},
+		e.Steps[2].Validator)
+
+	e = p.Explain("span", "color", "red")
+	assert.Empty(t, e.Output)
+	assert.Equal(t, RejectDenied, e.Reason)
+	require.Len(t, e.Steps, 1)
+	assert.True(t, e.Steps[0].Matched)
+
+	e = p.Explain("div", "-webkit-Transform", `\72 otate(360)`)
+	assert.Equal(t, "transform", e.Name)
+	assert.Equal(t, "-webkit-", e.Prefix)
+	assert.Equal(t, "rotate(360)", e.Normalized)
+	assert.Equal(t, `-webkit-Transform: \72 otate(360)`, e.Output)
+	require.Len(t, e.Steps, 1)
+	assert.True(t, e.Steps[0].Matched)
+	assert.Equal(t, "transform", e.Steps[0].Validator.Name)
+
+	e = p.Explain("div", "color", `\100072ed`)
+	assert.Equal(t, RejectUnicode, e.Reason)
+	assert.Empty(t, e.Steps)
+
+	e = p.Explain("p", "margin", "0")
+	assert.Equal(t, RejectNoPolicy, e.Reason)
+	assert.Empty(t, e.Steps)
+}
@@ -0,0 +1,207 @@
+package css
+
+import (
+	"slices"
+	"strings"
+)
+
+// gradientFunctions are gradient functions with their validators of the first
+// argument, which configures the gradient, and of stop positions.
+var gradientFunctions = map[string]struct {
+	config   func(args []string) bool
+	position func(value string) bool
+}{
+	"linear-gradient":           {isLinearGradientConfig, LengthHandler},
+	"repeating-linear-gradient": {isLinearGradientConfig, LengthHandler},
+	"radial-gradient":           {isRadialGradientConfig, LengthHandler},
+	"repeating-radial-gradient": {isRadialGradientConfig, LengthHandler},
+	"conic-gradient":            {isConicGradientConfig, isAnglePercentage},
+	"repeating-conic-gradient":  {isConicGradientConfig, isAnglePercentage},
+}
+
+var (
+	gradientSides  = []string{"left", "right", "top", "bottom"}
+	gradientShapes = []string{"circle", "ellipse"}
+	gradientSizes  = []string{
+		"closest-side", "closest-corner", "farthest-side", "farthest-corner",
+	}
+	positionKeywords = []string{"left", "center", "right", "top", "bottom"}
+)
+
+// isGradient returns true if value is a gradient of CSS Images Level 4, like
+// "linear-gradient(to right, red, 30%, blue 50% 60%)" or
+// "radial-gradient(circle at top left, red, blue)". Colors are checked by
+// ColorHandler, and stop positions by LengthHandler or AngleHandler.
+func isGradient(value string) bool {
+	if !strings.HasSuffix(value, ")") {
+		return false
+	}
+	name, args, ok := strings.Cut(value[:len(value)-1], "(")
+	fn, known := gradientFunctions[name]
+	if !ok || !known {
+		return false
+	}
+
+	split := splitTopLevel(args, ",")
+	if config := splitFields(split[0]); len(config) > 0 && !isColor(config[0]) {
+		if !fn.config(config) {
+			return false
+		}
+		split = split[1:]
+	}
+	return isColorStopList(split, fn.position)
+}
+
+// isColorStopList returns true if stops are color stops, with optional color
+// hints between them.
+func isColorStopList(stops []string, position func(string) bool) bool {
+	var colors int
+	hint := false
+	for i, stop := range stops {
+		fields := splitFields(stop)
+		switch {
+		case len(fields) == 1 && position(fields[0]):
+			// a color hint must be between color stops
+			if hint || i == 0 || i == len(stops)-1 {
+				return false
+			}
+			hint = true
+			continue
+		case len(fields) == 0 || len(fields) > 3 || !isColor(fields[0]):
+			return false
+		}
+
+		for _, p := range fields[1:] {
+			if !position(p) {
+				return false
+			}
+		}
+		colors++
+		hint = false
+	}
+	return colors >= 2
+}
+
+// isLinearGradientConfig returns true if args configure a linear gradient,
+// like "45deg" or "to top right in oklch".
+func isLinearGradientConfig(args []string) bool {
+	args, ok := cutInterpolation(args)
+	switch {
+	case !ok:
+		return false
+	case len(args) == 0:
+		return true
+	case len(args) == 1:
+		return AngleHandler(args[0])
+	case args[0] != "to" || len(args) > 3:
+		return false
+	}
+
+	sides := args[1:]
+	for _, side := range sides {
+		if !slices.Contains(gradientSides, side) {
+			return false
+		}
+	}
+	return len(sides) == 1 || isHorizontal(sides[0]) != isHorizontal(sides[1])
+}
+
+// isRadialGradientConfig returns true if args configure a radial gradient, like
+// "circle 10px at center" or "farthest-corner at 10% 20%".
+func isRadialGradientConfig(args []string) bool {
+	args, ok := cutInterpolation(args)
+	if !ok {
+		return false
+	}
+	args, ok = cutPosition(args)
+	if !ok {
+		return false
+	}
+
+	var shape, size bool
+	var lengths int
+	for _, arg := range args {
+		switch {
+		case slices.Contains(gradientShapes, arg) && !shape:
+			shape = true
+		case slices.Contains(gradientSizes, arg) && !size && lengths == 0:
+			size = true
+		case LengthHandler(arg) && !size && lengths < 2:
+			lengths++
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isConicGradientConfig returns true if args configure a conic gradient, like
+// "from 45deg at center".
+func isConicGradientConfig(args []string) bool {
+	args, ok := cutInterpolation(args)
+	if !ok {
+		return false
+	}
+	args, ok = cutPosition(args)
+	switch {
+	case !ok:
+		return false
+	case len(args) == 0:
+		return true
+	}
+	return len(args) == 2 && args[0] == "from" && AngleHandler(args[1])
+}
+
+// cutInterpolation returns args without color interpolation method, like
+// "in oklch longer hue", or false if the method isn't valid. The method is
+// either at the start or at the end of args.
+func cutInterpolation(args []string) ([]string, bool) {
+	i := slices.Index(args, "in")
+	if i < 0 {
+		return args, true
+	}
+
+	for _, n := range [...]int{4, 2} {
+		if i+n <= len(args) && (i == 0 || i+n == len(args)) &&
+			isColorInterpolation(args[i:i+n]) {
+			return slices.Concat(args[:i], args[i+n:]), true
+		}
+	}
+	return nil, false
+}
+
+// cutPosition returns args without position, like "at top left", at the end,
+// or false if the position isn't valid.
+func cutPosition(args []string) ([]string, bool) {
+	i := slices.Index(args, "at")
+	if i < 0 {
+		return args, true
+	}
+
+	position := args[i+1:]
+	if len(position) == 0 || len(position) > 4 {
+		return nil, false
+	}
+	for _, p := range position {
+		if !slices.Contains(positionKeywords, p) && !LengthHandler(p) {
+			return nil, false
+		}
+	}
+	return args[:i], true
+}
+
+// isAnglePercentage returns true if value is an angle or a percentage.
+func isAnglePercentage(value string) bool {
+	return AngleHandler(value) || isPercentage(value)
+}
+
+func isHorizontal(side string) bool {
+	return side == "left" || side == "right"
+}
+
+// splitFields returns non-empty fields of value, separated by spaces outside
+// of parentheses.
+func splitFields(value string) []string {
+	fields := splitTopLevel(strings.TrimSpace(value), " ")
+	return slices.DeleteFunc(fields, func(s string) bool { return s == "" })
+}
@@ -0,0 +1,87 @@
+package css
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGradient(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"linear-gradient(red, blue)", true},
+		{"linear-gradient(45deg, red, blue)", true},
+		{"linear-gradient(0.25turn, red, blue)", true},
+		{"linear-gradient(to right, red, blue)", true},
+		{"linear-gradient(to top left, red, blue)", true},
+		{"linear-gradient(to left right, red, blue)", false},
+		{"linear-gradient(to top top, red, blue)", false},
+		{"linear-gradient(to center, red, blue)", false},
+		{"linear-gradient(45px, red, blue)", false},
+		{"linear-gradient(in oklch, red, blue)", true},
+		{"linear-gradient(to right in hsl longer hue, red, blue)", true},
+		{"linear-gradient(in oklch 45deg, red, blue)", true},
+		{"linear-gradient(to right in foo, red, blue)", false},
+		{"linear-gradient(red 10%, blue 90%)", true},
+		{"linear-gradient(red 10% 20%, blue 90%)", true},
+		{"linear-gradient(red 10% 20% 30%, blue)", false},
+		{"linear-gradient(red, 30%, blue)", true},
+		{"linear-gradient(red, 30%, 40%, blue)", false},
+		{"linear-gradient(30%, red, blue)", false},
+		{"linear-gradient(red, blue, 30%)", false},
+		{"linear-gradient(red calc(100% - 10px), blue)", true},
+		{"linear-gradient(red 10deg, blue)", false},
+		{"linear-gradient(rgb(255 0 0 / 50%), oklch(70% 0.1 200))", true},
+		{"linear-gradient(red)", false},
+		{"linear-gradient(red, foo)", false},
+		{"linear-gradient(red, , blue)", false},
+		{"linear-gradient()", false},
+		{"repeating-linear-gradient(red, blue 10px)", true},
+		{"radial-gradient(red, blue)", true},
+		{"radial-gradient(circle, red, blue)", true},
+		{"radial-gradient(circle 10px, red, blue)", true},
+		{"radial-gradient(ellipse 10px 20%, red, blue)", true},
+		{"radial-gradient(10px 20px 30px, red, blue)", false},
+		{"radial-gradient(farthest-corner at 10% 20%, red, blue)", true},
+		{"radial-gradient(circle closest-side at top left, red, blue)", true},
+		{"radial-gradient(at center, red, blue)", true},
+		{"radial-gradient(at, red, blue)", false},
+		{"radial-gradient(at middle, red, blue)", false},
+		{"radial-gradient(circle circle, red, blue)", false},
+		{"radial-gradient(closest-side 10px, red, blue)", false},
+		{"repeating-radial-gradient(circle, red 0, blue 10px)", true},
+		{"conic-gradient(red, blue)", true},
+		{"conic-gradient(from 45deg, red, blue)", true},
+		{"conic-gradient(from 45deg at 10% 20%, red 0deg, blue 50%)", true},
+		{"conic-gradient(from 45px, red, blue)", false},
+		{"conic-gradient(red 10px, blue)", false},
+		{"repeating-conic-gradient(red 0 15deg, blue 15deg 30deg)", true},
+		{"-webkit-linear-gradient(red, blue)", false},
+		{"linear-gradient(red, blue", false},
+		{"foo-gradient(red, blue)", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isGradient(tt.value))
+		})
+	}
+}
+
+func TestIsGradient_properties(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("background", "background-image", "border-image",
+		"list-style-image").Globally()
+
+	for _, style := range []string{
+		"background-image: linear-gradient(to right, red, blue)",
+		"list-style-image: radial-gradient(circle, red, blue)",
+		"background: linear-gradient(45deg, rgb(255 0 0 / 50%) 10%, blue) no-repeat",
+		"border-image: linear-gradient(red, blue) 30",
+	} {
+		assert.Equal(t, style, p.Sanitize("div", style))
+	}
+	assert.Empty(t, p.Sanitize("div", "background-image: linear-gradient(red)"))
+}
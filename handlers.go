@@ -31,11 +31,13 @@ package css
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 )
 
 var (
 	defaultStyleHandlers = map[string]func(string) bool{
+		"--":                         CustomPropertyHandler,
 		"align-content":              AlignContentHandler,
 		"align-items":                AlignItemsHandler,
 		"align-self":                 AlignSelfHandler,
@@ -49,6 +51,7 @@ var (
 		"animation-name":             AnimationNameHandler,
 		"animation-play-state":       AnimationPlayStateHandler,
 		"animation-timing-function":  TimingFunctionHandler,
+		"aspect-ratio":               AspectRatioHandler,
 		"backface-visibility":        BackfaceVisibilityHandler,
 		"background":                 BackgroundHandler,
 		"background-attachment":      BackgroundAttachmentHandler,
@@ -60,7 +63,20 @@ var (
 		"background-position":        BackgroundPositionHandler,
 		"background-repeat":          BackgroundRepeatHandler,
 		"background-size":            BackgroundSizeHandler,
+		"block-size":                 HeightHandler,
 		"border":                     BorderHandler,
+		"border-block":               BorderSideHandler,
+		"border-block-color":         BorderAxisColorHandler,
+		"border-block-end":           BorderSideHandler,
+		"border-block-end-color":     ColorHandler,
+		"border-block-end-style":     BorderSideStyleHandler,
+		"border-block-end-width":     BorderSideWidthHandler,
+		"border-block-start":         BorderSideHandler,
+		"border-block-start-color":   ColorHandler,
+		"border-block-start-style":   BorderSideStyleHandler,
+		"border-block-start-width":   BorderSideWidthHandler,
+		"border-block-style":         BorderAxisStyleHandler,
+		"border-block-width":         BorderAxisWidthHandler,
 		"border-bottom":              BorderSideHandler,
 		"border-bottom-color":        ColorHandler,
 		"border-bottom-left-radius":  BorderSideRadiusHandler,
@@ -69,12 +85,26 @@ var (
 		"border-bottom-width":        BorderSideWidthHandler,
 		"border-collapse":            BorderCollapseHandler,
 		"border-color":               ColorHandler,
+		"border-end-end-radius":      BorderSideRadiusHandler,
+		"border-end-start-radius":    BorderSideRadiusHandler,
 		"border-image":               BorderImageHandler,
 		"border-image-outset":        BorderImageOutsetHandler,
 		"border-image-repeat":        BorderImageRepeatHandler,
 		"border-image-slice":         BorderImageSliceHandler,
 		"border-image-source":        ImageHandler,
 		"border-image-width":         BorderImageWidthHandler,
+		"border-inline":              BorderSideHandler,
+		"border-inline-color":        BorderAxisColorHandler,
+		"border-inline-end":          BorderSideHandler,
+		"border-inline-end-color":    ColorHandler,
+		"border-inline-end-style":    BorderSideStyleHandler,
+		"border-inline-end-width":    BorderSideWidthHandler,
+		"border-inline-start":        BorderSideHandler,
+		"border-inline-start-color":  ColorHandler,
+		"border-inline-start-style":  BorderSideStyleHandler,
+		"border-inline-start-width":  BorderSideWidthHandler,
+		"border-inline-style":        BorderAxisStyleHandler,
+		"border-inline-width":        BorderAxisWidthHandler,
 		"border-left":                BorderSideHandler,
 		"border-left-color":          ColorHandler,
 		"border-left-style":          BorderSideStyleHandler,
@@ -85,6 +115,8 @@ var (
 		"border-right-style":         BorderSideStyleHandler,
 		"border-right-width":         BorderSideWidthHandler,
 		"border-spacing":             BorderSpacingHandler,
+		"border-start-end-radius":    BorderSideRadiusHandler,
+		"border-start-start-radius":  BorderSideRadiusHandler,
 		"border-style":               BorderStyleHandler,
 		"border-top":                 BorderSideHandler,
 		"border-top-color":           ColorHandler,
@@ -130,17 +162,25 @@ var (
 		"float":                      FloatHandler,
 		"font":                       FontHandler,
 		"font-family":                FontFamilyHandler,
+		"font-feature-settings":      FontFeatureSettingsHandler,
 		"font-kerning":               FontKerningHandler,
 		"font-language-override":     FontLanguageOverrideHandler,
+		"font-optical-sizing":        FontOpticalSizingHandler,
 		"font-size":                  FontSizeHandler,
 		"font-size-adjust":           FontSizeAdjustHandler,
 		"font-stretch":               FontStretchHandler,
 		"font-style":                 FontStyleHandler,
 		"font-synthesis":             FontSynthesisHandler,
 		"font-variant":               FontVariantHandler,
+		"font-variant-alternates":    FontVariantAlternatesHandler,
 		"font-variant-caps":          FontVariantCapsHandler,
+		"font-variant-east-asian":    FontVariantEastAsianHandler,
+		"font-variant-ligatures":     FontVariantLigaturesHandler,
+		"font-variant-numeric":       FontVariantNumericHandler,
 		"font-variant-position":      FontVariantPositionHandler,
+		"font-variation-settings":    FontVariationSettingsHandler,
 		"font-weight":                FontWeightHandler,
+		"gap":                        GapHandler,
 		"grid":                       GridHandler,
 		"grid-area":                  GridAreaHandler,
 		"grid-auto-columns":          GridAutoColumnsHandler,
@@ -161,10 +201,21 @@ var (
 		"grid-template-rows":         GridTemplateRowsHandler,
 		"hanging-punctuation":        HangingPunctuationHandler,
 		"height":                     HeightHandler,
+		"hyphenate-character":        HyphenateCharacterHandler,
 		"hyphens":                    HyphensHandler,
 		"image-rendering":            ImageRenderingHandler,
+		"inline-size":                WidthHandler,
+		"inset":                      InsetHandler,
+		"inset-block":                InsetAxisHandler,
+		"inset-block-end":            SideHandler,
+		"inset-block-start":          SideHandler,
+		"inset-inline":               InsetAxisHandler,
+		"inset-inline-end":           SideHandler,
+		"inset-inline-start":         SideHandler,
 		"isolation":                  IsolationHandler,
 		"justify-content":            JustifyContentHandler,
+		"justify-items":              JustifyItemsHandler,
+		"justify-self":               JustifySelfHandler,
 		"left":                       SideHandler,
 		"letter-spacing":             LetterSpacingHandler,
 		"line-break":                 LineBreakHandler,
@@ -174,15 +225,36 @@ var (
 		"list-style-position":        ListStylePositionHandler,
 		"list-style-type":            ListStyleTypeHandler,
 		"margin":                     MarginHandler,
+		"margin-block":               MarginAxisHandler,
+		"margin-block-end":           MarginSideHandler,
+		"margin-block-start":         MarginSideHandler,
 		"margin-bottom":              MarginSideHandler,
+		"margin-inline":              MarginAxisHandler,
+		"margin-inline-end":          MarginSideHandler,
+		"margin-inline-start":        MarginSideHandler,
 		"margin-left":                MarginSideHandler,
 		"margin-right":               MarginSideHandler,
 		"margin-top":                 MarginSideHandler,
+		"max-block-size":             MaxHeightWidthHandler,
 		"max-height":                 MaxHeightWidthHandler,
+		"max-inline-size":            MaxHeightWidthHandler,
 		"max-width":                  MaxHeightWidthHandler,
+		"min-block-size":             MinHeightWidthHandler,
 		"min-height":                 MinHeightWidthHandler,
+		"min-inline-size":            MinHeightWidthHandler,
 		"min-width":                  MinHeightWidthHandler,
 		"mix-blend-mode":             MixBlendModeHandler,
+		"mso-ansi-font-size":         FontSizeHandler,
+		"mso-border-alt":             BorderHandler,
+		"mso-color-alt":              ColorHandler,
+		"mso-hide":                   MsoHideHandler,
+		"mso-line-height-rule":       MsoLineHeightRuleHandler,
+		"mso-margin-bottom-alt":      MarginSideHandler,
+		"mso-margin-top-alt":         MarginSideHandler,
+		"mso-padding-alt":            PaddingHandler,
+		"mso-table-lspace":           LengthHandler,
+		"mso-table-rspace":           LengthHandler,
+		"mso-text-raise":             LengthHandler,
 		"object-fit":                 ObjectFitHandler,
 		"object-position":            ObjectPositionHandler,
 		"opacity":                    OpacityHandler,
@@ -194,11 +266,19 @@ var (
 		"outline-style":              OutlineStyleHandler,
 		"outline-width":              OutlineWidthHandler,
 		"overflow":                   OverflowHandler,
+		"overflow-block":             OverflowXYHandler,
+		"overflow-inline":            OverflowXYHandler,
 		"overflow-wrap":              OverflowWrapHandler,
 		"overflow-x":                 OverflowXYHandler,
 		"overflow-y":                 OverflowXYHandler,
 		"padding":                    PaddingHandler,
+		"padding-block":              PaddingAxisHandler,
+		"padding-block-end":          PaddingSideHandler,
+		"padding-block-start":        PaddingSideHandler,
 		"padding-bottom":             PaddingSideHandler,
+		"padding-inline":             PaddingAxisHandler,
+		"padding-inline-end":         PaddingSideHandler,
+		"padding-inline-start":       PaddingSideHandler,
 		"padding-left":               PaddingSideHandler,
 		"padding-right":              PaddingSideHandler,
 		"padding-top":                PaddingSideHandler,
@@ -207,11 +287,15 @@ var (
 		"page-break-inside":          PageBreakInsideHandler,
 		"perspective":                PerspectiveHandler,
 		"perspective-origin":         PerspectiveOriginHandler,
+		"place-content":              PlaceContentHandler,
+		"place-items":                PlaceItemsHandler,
+		"place-self":                 PlaceSelfHandler,
 		"pointer-events":             PointerEventsHandler,
 		"position":                   PositionHandler,
 		"quotes":                     QuotesHandler,
 		"resize":                     ResizeHandler,
 		"right":                      SideHandler,
+		"row-gap":                    ColumnGapHandler,
 		"scroll-behavior":            ScrollBehaviorHandler,
 		"tab-size":                   TabSizeHandler,
 		"table-layout":               TableLayoutHandler,
@@ -221,13 +305,24 @@ var (
 		"text-decoration":            TextDecorationHandler,
 		"text-decoration-color":      ColorHandler,
 		"text-decoration-line":       TextDecorationLineHandler,
+		"text-decoration-skip-ink":   TextDecorationSkipInkHandler,
 		"text-decoration-style":      TextDecorationStyleHandler,
+		"text-decoration-thickness":  TextDecorationThicknessHandler,
+		"text-emphasis":              TextEmphasisHandler,
+		"text-emphasis-color":        ColorHandler,
+		"text-emphasis-position":     TextEmphasisPositionHandler,
+		"text-emphasis-style":        TextEmphasisStyleHandler,
 		"text-indent":                TextIndentHandler,
 		"text-justify":               TextJustifyHandler,
 		"text-orientation":           TextOrientationHandler,
 		"text-overflow":              TextOverflowHandler,
 		"text-shadow":                TextShadowHandler,
 		"text-transform":             TextTransformHandler,
+		"text-underline-offset":      TextUnderlineOffsetHandler,
+		"text-underline-position":    TextUnderlinePositionHandler,
+		"text-wrap":                  TextWrapHandler,
+		"text-wrap-mode":             TextWrapModeHandler,
+		"text-wrap-style":            TextWrapStyleHandler,
 		"top":                        SideHandler,
 		"transform":                  TransformHandler,
 		"transform-origin":           TransformOriginHandler,
@@ -242,6 +337,7 @@ var (
 		"vertical-align":             VerticalAlignHandler,
 		"visibility":                 VisiblityHandler,
 		"white-space":                WhiteSpaceHandler,
+		"white-space-collapse":       WhiteSpaceCollapseHandler,
 		"widows":                     OrphansHandler,
 		"width":                      WidthHandler,
 		"word-break":                 WordBreakHandler,
@@ -267,7 +363,7 @@ var (
 		"lemonchiffon", "lightblue", "lightcoral", "lightcyan",
 		"lightgoldenrodyellow", "lightgray", "lightgrey", "lightgreen",
 		"lightpink", "lightsalmon", "lightseagreen", "lightskyblue",
-		"lightslategray", "lightslategrey", "lightsteeelblue", "lightyellow",
+		"lightslategray", "lightslategrey", "lightsteelblue", "lightyellow",
 		"lime", "limegreen", "linen", "magenta", "maroon", "mediumaquamarine",
 		"mediumblue", "mediumorchid", "mediumpurple", "mediumseagreen",
 		"mediumslateblue", "mediumspringgreen", "mediumturquoise",
@@ -284,12 +380,17 @@ var (
 	}
 
 	Alpha             = regexp.MustCompile(`^[a-z]+$`)
+	Angle             = regexp.MustCompile(`^([\-]?([0-9]+|[0-9]*[\.][0-9]+)(deg|grad|rad|turn)|0)$`)
 	Blur              = regexp.MustCompile(`^blur\([0-9]+px\)$`)
 	BrightnessCont    = regexp.MustCompile(`^(brightness|contrast)\([0-9]+\%\)$`)
 	Count             = regexp.MustCompile(`^[0-9]+[\.]?[0-9]*$`)
 	CubicBezier       = regexp.MustCompile(`^cubic-bezier\(([ ]*(0(.[0-9]+)?|1(.0)?),){3}[ ]*(0(.[0-9]+)?|1)\)$`)
+	CustomProperty    = regexp.MustCompile(`^[a-z0-9#%.,() +*/\-]+$`)
+	CustomPropertyURL = regexp.MustCompile(`(url|src|image|image-set|element|cross-fade|expression)\(`)
 	Digits            = regexp.MustCompile(`^digits [2-4]$`)
 	DropShadow        = regexp.MustCompile(`drop-shadow\(([-]?[0-9]+px) ([-]?[0-9]+px)( [-]?[0-9]+px)?( ([-]?[0-9]+px))?`)
+	FeatureIndex      = regexp.MustCompile(`^[0-9]+$`)
+	FeatureValueName  = regexp.MustCompile(`^-?[a-z_][a-z0-9_\-]*$`)
 	Font              = regexp.MustCompile(`^('[a-z \-]+'|[a-z \-]+)$`)
 	Grayscale         = regexp.MustCompile(`^grayscale\(([0-9]{1,2}|100)%\)$`)
 	GridTemplateAreas = regexp.MustCompile(`^['"]?[a-z ]+['"]?$`)
@@ -298,22 +399,26 @@ var (
 	HSLA              = regexp.MustCompile(`^hsla\(([ ]*[012]?[0-9]{1,2}|3[0-5][0-9]|360),[ ]*([0-9]{0,2}|100)\%,[ ]*([0-9]{0,2}|100)\%,[ ]*(1|1\.0|0|(0\.[0-9]+))\)$`)
 	HueRotate         = regexp.MustCompile(`^hue-rotate\(([12]?[0-9]{1,2}|3[0-5][0-9]|360)?\)$`)
 	Invert            = regexp.MustCompile(`^invert\(([0-9]{1,2}|100)%\)$`)
-	Length            = regexp.MustCompile(`^[\-]?([0-9]+|[0-9]*[\.][0-9]+)(%|cm|mm|in|px|pt|pc|em|ex|ch|rem|vw|vh|vmin|vmax|deg|rad|turn)?$`)
+	Length            = regexp.MustCompile(`^[\-]?([0-9]+|[0-9]*[\.][0-9]+)(%|cm|mm|in|px|pt|pc|em|ex|ch|rem|vw|vh|vmin|vmax)?$`)
 	Matrix            = regexp.MustCompile(`^matrix\(([ ]*[0-9]+[\.]?[0-9]*,){5}([ ]*[0-9]+[\.]?[0-9]*)\)$`)
 	Matrix3D          = regexp.MustCompile(`^matrix3d\(([ ]*[0-9]+[\.]?[0-9]*,){15}([ ]*[0-9]+[\.]?[0-9]*)\)$`)
 	NegTime           = regexp.MustCompile(`^[\-]?[0-9]+[\.]?[0-9]*(s|ms)?$`)
 	Numeric           = regexp.MustCompile(`^[0-9]+$`)
 	NumericDecimal    = regexp.MustCompile(`^[0-9\.]+$`)
 	Opactiy           = regexp.MustCompile(`^opacity\(([0-9]{1,2}|100)%\)$`)
+	OpenTypeTag       = regexp.MustCompile(`^("[\x20-\x7e]{4}"|'[\x20-\x7e]{4}')$`)
 	Perspective       = regexp.MustCompile(`perspective\(`)
 	Position          = regexp.MustCompile(`^[\-]*[0-9]+[cm|mm|in|px|pt|pc\%]* [[\-]*[0-9]+[cm|mm|in|px|pt|pc\%]*]*$`)
 	Opacity           = regexp.MustCompile(`^(0[.]?[0-9]*)|(1.0)$`)
 	QuotedAlpha       = regexp.MustCompile(`^["'][a-z]+["']$`)
+	QuotedString      = regexp.MustCompile(`^("[^"\\\n]*"|'[^'\\\n]*')$`)
 	Quotes            = regexp.MustCompile(`^([ ]*["'][\x{0022}\x{0027}\x{2039}\x{2039}\x{203A}\x{00AB}\x{00BB}\x{2018}\x{2019}\x{201C}-\x{201E}]["'] ["'][\x{0022}\x{0027}\x{2039}\x{2039}\x{203A}\x{00AB}\x{00BB}\x{2018}\x{2019}\x{201C}-\x{201E}]["'])+$`)
+	RatioNumber       = regexp.MustCompile(`^([0-9]+|[0-9]*\.[0-9]+)$`)
 	Rect              = regexp.MustCompile(`^rect\([0-9]+px,[ ]*[0-9]+px,[ ]*[0-9]+px,[ ]*[0-9]+px\)$`)
 	RGB               = regexp.MustCompile(`^rgb\(([ ]*((([0-9]{1,2}|100)\%)|(([01]?[0-9]{1,2})|(2[0-4][0-9])|(25[0-5]))),){2}([ ]*((([0-9]{1,2}|100)\%)|(([01]?[0-9]{1,2})|(2[0-4][0-9])|(25[0-5]))))\)$`)
 	RGBA              = regexp.MustCompile(`^rgba\(([ ]*((([0-9]{1,2}|100)\%)|(([01]?[0-9]{1,2})|(2[0-4][0-9])|(25[0-5]))),){3}[ ]*(1(\.0)?|0|(0\.[0-9]+))\)$`)
 	Rotate            = regexp.MustCompile(`^rotate(x|y|z)?\(([12]?|3[0-5][0-9]|360)\)$`)
+	RotateAngle       = regexp.MustCompile(`^rotate(x|y|z)?\((.+)\)$`)
 	Rotate3D          = regexp.MustCompile(`^rotate3d\(([ ]?(1(\.0)?|0\.[0-9]+),){3}([12]?|3[0-5][0-9]|360)\)$`)
 	Saturate          = regexp.MustCompile(`^saturate\([0-9]+%\)$`)
 	Sepia             = regexp.MustCompile(`^sepia\(([0-9]{1,2}|100)%\)$`)
@@ -323,34 +428,338 @@ var (
 	Time              = regexp.MustCompile(`^[0-9]+[\.]?[0-9]*(s|ms)?$`)
 	TransitionProp    = regexp.MustCompile(`^([a-zA-Z]+,[ ]?)*[a-zA-Z]+$`)
 	TranslateScale    = regexp.MustCompile(`(translate|translate3d|translatex|translatey|translatez|scale|scale3d|scalex|scaley|scalez)\(`)
-	URL               = regexp.MustCompile(`^url\([\"\']?((https|http)[a-z0-9\.\\/_:]+[\"\']?)\)$`)
+	URLFunction       = regexp.MustCompile(`^url\(\s*("[^"\\\n]*"|'[^'\\\n]*'|[^\s"'()\\]*)\s*\)$`)
+	VariationValue    = regexp.MustCompile(`^[\-+]?([0-9]+|[0-9]*\.[0-9]+)$`)
 	ZIndex            = regexp.MustCompile(`^[\-]?[0-9]+$`)
 )
 
+// Positions of CSS Box Alignment Level 3.
+var (
+	contentPositions = []string{"center", "start", "end", "flex-start", "flex-end"}
+	selfPositions    = []string{
+		"center", "start", "end", "self-start", "self-end", "flex-start", "flex-end",
+	}
+	leftRight = []string{"left", "right"}
+
+	justifyContentPositions = slices.Concat(contentPositions, leftRight)
+	justifySelfPositions    = slices.Concat(selfPositions, leftRight)
+)
+
+// Keyword groups of font-variant longhands. A value can contain at most one
+// keyword of every group.
+var (
+	fontVariantCaps = [][]string{{
+		"small-caps", "all-small-caps", "petite-caps", "all-petite-caps",
+		"unicase", "titling-caps",
+	}}
+	fontVariantEastAsian = [][]string{
+		{"jis78", "jis83", "jis90", "jis04", "simplified", "traditional"},
+		{"full-width", "proportional-width"},
+		{"ruby"},
+	}
+	fontVariantLigatures = [][]string{
+		{"common-ligatures", "no-common-ligatures"},
+		{"discretionary-ligatures", "no-discretionary-ligatures"},
+		{"historical-ligatures", "no-historical-ligatures"},
+		{"contextual", "no-contextual"},
+	}
+	fontVariantNumeric = [][]string{
+		{"lining-nums", "oldstyle-nums"},
+		{"proportional-nums", "tabular-nums"},
+		{"diagonal-fractions", "stacked-fractions"},
+		{"ordinal"},
+		{"slashed-zero"},
+	}
+	fontVariantPosition  = [][]string{{"sub", "super"}}
+	fontVariantAlternate = [][]string{
+		{"stylistic()"},
+		{"historical-forms"},
+		{"styleset()"},
+		{"character-variant()"},
+		{"swash()"},
+		{"ornaments()"},
+		{"annotation()"},
+	}
+
+	fontVariant = slices.Concat(fontVariantLigatures, fontVariantCaps,
+		fontVariantNumeric, fontVariantAlternate, fontVariantEastAsian,
+		fontVariantPosition)
+)
+
+// fontVariantFunctions are functions of font-variant-alternates with true if
+// they accept a list of names.
+var fontVariantFunctions = map[string]bool{
+	"stylistic":         false,
+	"styleset":          true,
+	"character-variant": true,
+	"swash":             false,
+	"ornaments":         false,
+	"annotation":        false,
+}
+
 func multiSplit(value string, seps ...string) []string {
 	curArray := []string{value}
 	for _, i := range seps {
 		newArray := []string{}
 		for _, j := range curArray {
-			newArray = append(newArray, strings.Split(j, i)...)
+			newArray = append(newArray, splitTopLevel(j, i)...)
 		}
 		curArray = newArray
 	}
 	return curArray
 }
 
+// recursiveCheck returns true if value can be split into consecutive groups of
+// tokens, each of which is allowed by any of funcs. It remembers which tokens
+// can start a group, so it's quadratic in the number of tokens, not
+// exponential.
 func recursiveCheck(value []string, funcs []func(string) bool) bool {
-	for i := range len(value) {
-		tempVal := strings.Join(value[:i+1], " ")
-		for _, j := range funcs {
-			if j(tempVal) && (len(value[i+1:]) == 0 || recursiveCheck(value[i+1:], funcs)) {
-				return true
+	if len(value) == 0 {
+		return false
+	}
+
+	// reachable[i] is true if value[:i] can be split into allowed groups
+	reachable := make([]bool, len(value)+1)
+	reachable[0] = true
+	group := tokenGroups(value)
+	for start := range len(value) {
+		if !reachable[start] {
+			continue
+		}
+		for end := start + 1; end <= len(value); end++ {
+			if reachable[end] {
+				continue
+			}
+			tempVal := group(start, end)
+			for _, j := range funcs {
+				if j(tempVal) {
+					reachable[end] = true
+					break
+				}
 			}
 		}
 	}
+	return reachable[len(value)]
+}
+
+// unorderedCheck is like recursiveCheck, but every function can allow at most
+// one group, like components of "a || b || c" grammar.
+func unorderedCheck(value []string, funcs []func(string) bool) bool {
+	if len(value) == 0 || len(funcs) > 16 {
+		return false
+	}
+
+	// reachable[i][used] is true if value[:i] can be split into groups allowed
+	// by funcs in used bitmask
+	reachable := make([][]bool, len(value)+1)
+	for i := range reachable {
+		reachable[i] = make([]bool, 1<<len(funcs))
+	}
+	reachable[0][0] = true
+	group := tokenGroups(value)
+	for start := range len(value) {
+		if !slices.Contains(reachable[start], true) {
+			continue
+		}
+		for end := start + 1; end <= len(value); end++ {
+			// every function is called once for every group
+			tempVal := group(start, end)
+			var allowed int
+			for i, j := range funcs {
+				if j(tempVal) {
+					allowed |= 1 << i
+				}
+			}
+			if allowed == 0 {
+				continue
+			}
+			for used, ok := range reachable[start] {
+				for i := range funcs {
+					if ok && used&(1<<i) == 0 && allowed&(1<<i) != 0 {
+						reachable[end][used|1<<i] = true
+					}
+				}
+			}
+		}
+	}
+	return slices.Contains(reachable[len(value)], true)
+}
+
+// tokenGroups returns a function, which returns tokens value[start:end]
+// joined by spaces, without joining them again for every group.
+func tokenGroups(value []string) func(start, end int) string {
+	joined := strings.Join(value, " ")
+	offsets := make([]int, len(value)+1)
+	for i, v := range value {
+		offsets[i+1] = offsets[i] + len(v) + 1
+	}
+	return func(start, end int) string {
+		return joined[offsets[start] : offsets[end]-1]
+	}
+}
+
+// isAxisPair returns true if value is one or two values of the start and end
+// sides of an axis, like "margin-inline: 1px 2px", each allowed by handler.
+func isAxisPair(value string, handler func(string) bool) bool {
+	return isBoxSides(value, 2, handler)
+}
+
+// isBoxSides returns true if value is from one to maxSides values of box sides,
+// each allowed by handler. Global keywords are allowed only alone.
+func isBoxSides(value string, maxSides int, handler func(string) bool) bool {
+	if in([]string{value}, []string{"initial", "inherit"}) {
+		return true
+	}
+
+	splitVals := splitSpaces(value)
+	if len(splitVals) > maxSides {
+		return false
+	}
+	for _, v := range splitVals {
+		if in([]string{v}, []string{"initial", "inherit"}) || !handler(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlignment returns true if value is one of keywords or an optional
+// overflow position, like "safe", followed by one of positions. "baseline" in
+// keywords also allows "first baseline" and "last baseline".
+func isAlignment(value string, keywords, positions []string) bool {
+	values := []string{"initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+
+	splitVals := splitSpaces(value)
+	switch len(splitVals) {
+	case 1:
+		return slices.Contains(keywords, value) || slices.Contains(positions, value)
+	case 2:
+		if splitVals[1] == "baseline" {
+			return slices.Contains(keywords, "baseline") &&
+				(splitVals[0] == "first" || splitVals[0] == "last")
+		}
+		return (splitVals[0] == "safe" || splitVals[0] == "unsafe") &&
+			slices.Contains(positions, splitVals[1])
+	}
+	return false
+}
+
+// isPlacement returns true if value is a value of align handler, optionally
+// followed by a value of justify handler.
+func isPlacement(value string, align, justify func(string) bool) bool {
+	if align(value) {
+		return true
+	}
+
+	splitVals := splitSpaces(value)
+	for i := 1; i < len(splitVals); i++ {
+		alignValue := strings.Join(splitVals[:i], " ")
+		justifyValue := strings.Join(splitVals[i:], " ")
+		if !isGlobalKeyword(alignValue) && !isGlobalKeyword(justifyValue) &&
+			align(alignValue) && justify(justifyValue) {
+			return true
+		}
+	}
 	return false
 }
 
+// isRatio returns true if value is a ratio, like "16/9" or "1.5".
+func isRatio(value string) bool {
+	splitVals := splitTopLevel(value, "/")
+	if value == "" || len(splitVals) > 2 {
+		return false
+	}
+	for _, v := range splitVals {
+		v = strings.TrimSpace(v)
+		if !RatioNumber.MatchString(v) && !isMath(v, mathNumber) {
+			return false
+		}
+	}
+	return true
+}
+
+func isGlobalKeyword(value string) bool {
+	return value == "initial" || value == "inherit"
+}
+
+// isTagList returns true if value is a comma separated list of OpenType tags,
+// each followed by a value allowed by handler. The value is optional if
+// optional is true.
+func isTagList(value string, handler func(string) bool, optional bool) bool {
+	for _, setting := range splitTopLevel(value, ",") {
+		splitVals := splitFields(setting)
+		switch {
+		case len(splitVals) == 0 || len(splitVals) > 2:
+			return false
+		case !isOpenTypeTag(splitVals[0]):
+			return false
+		case len(splitVals) == 1:
+			if !optional {
+				return false
+			}
+		case !handler(splitVals[1]):
+			return false
+		}
+	}
+	return true
+}
+
+// isOpenTypeTag returns true if value is a quoted tag of four printable ASCII
+// characters, like "liga", without quotes, backslash and comma inside.
+func isOpenTypeTag(value string) bool {
+	return OpenTypeTag.MatchString(value) &&
+		!strings.ContainsAny(value[1:len(value)-1], "\"'\\,")
+}
+
+// isKeywordGroups returns true if value is space separated keywords, with at
+// most one keyword of every group.
+func isKeywordGroups(value string, groups [][]string) bool {
+	used := make([]bool, len(groups))
+	splitVals := splitSpaces(value)
+	for _, v := range splitVals {
+		keyword, ok := fontVariantKeyword(v)
+		if !ok {
+			return false
+		}
+		i := slices.IndexFunc(groups, func(group []string) bool {
+			return slices.Contains(group, keyword)
+		})
+		if i < 0 || used[i] {
+			return false
+		}
+		used[i] = true
+	}
+	return len(splitVals) > 0
+}
+
+// fontVariantKeyword returns value itself, or name of font-variant-alternates
+// function with "()", like "styleset()", if value is a valid call of the
+// function.
+func fontVariantKeyword(value string) (string, bool) {
+	name, args, ok := strings.Cut(value, "(")
+	if !ok {
+		return value, true
+	}
+
+	list, known := fontVariantFunctions[name]
+	if !known || !strings.HasSuffix(args, ")") {
+		return "", false
+	}
+	names := splitTopLevel(args[:len(args)-1], ",")
+	if len(names) > 1 && !list {
+		return "", false
+	}
+	for _, n := range names {
+		if !FeatureValueName.MatchString(strings.TrimSpace(n)) {
+			return "", false
+		}
+	}
+	return name + "()", true
+}
+
 func in(value []string, arr []string) bool {
 	for _, i := range value {
 		foundString := false
@@ -366,6 +775,31 @@ func in(value []string, arr []string) bool {
 	return true
 }
 
+// splitSpaces splits value by spaces outside of parentheses, so functions, like
+// "calc(100% - 20px)", aren't split.
+func splitSpaces(value string) []string {
+	return splitTopLevel(value, " ")
+}
+
+// splitTopLevel splits value by sep outside of parentheses.
+func splitTopLevel(value, sep string) []string {
+	var split []string
+	var depth, start int
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '(':
+			depth++
+		case value[i] == ')':
+			depth = max(depth-1, 0)
+		case depth == 0 && strings.HasPrefix(value[i:], sep):
+			split = append(split, value[start:i])
+			start = i + len(sep)
+			i = start - 1
+		}
+	}
+	return append(split, value[start:])
+}
+
 func splitValues(value string) []string {
 	values := strings.Split(value, ",")
 	newValues := []string{}
@@ -388,29 +822,20 @@ func BaseHandler(value string) bool {
 
 func AlignContentHandler(value string) bool {
 	values := []string{
-		"stretch", "center", "flex-start",
-		"flex-end", "space-between", "space-around", "initial", "inherit",
+		"normal", "baseline", "space-between", "space-around", "space-evenly",
+		"stretch",
 	}
-	splitVals := splitValues(value)
-	return in(splitVals, values)
+	return isAlignment(value, values, contentPositions)
 }
 
 func AlignItemsHandler(value string) bool {
-	values := []string{
-		"stretch", "center", "flex-start",
-		"flex-end", "baseline", "initial", "inherit",
-	}
-	splitVals := splitValues(value)
-	return in(splitVals, values)
+	values := []string{"normal", "stretch", "baseline"}
+	return isAlignment(value, values, selfPositions)
 }
 
 func AlignSelfHandler(value string) bool {
-	values := []string{
-		"auto", "stretch", "center", "flex-start",
-		"flex-end", "baseline", "initial", "inherit",
-	}
-	splitVals := splitValues(value)
-	return in(splitVals, values)
+	values := []string{"auto", "normal", "stretch", "baseline"}
+	return isAlignment(value, values, selfPositions)
 }
 
 func AllHandler(value string) bool {
@@ -419,12 +844,16 @@ func AllHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+func AngleHandler(value string) bool {
+	return Angle.MatchString(value) || isMath(value, mathAngle)
+}
+
 func AnimationHandler(value string) bool {
 	values := []string{"initial", "inherit"}
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		AnimationNameHandler,
 		AnimationDurationHandler,
@@ -439,7 +868,7 @@ func AnimationHandler(value string) bool {
 }
 
 func AnimationDelayHandler(value string) bool {
-	if NegTime.MatchString(value) {
+	if NegTime.MatchString(value) || isMath(value, mathTime) {
 		return true
 	}
 	values := []string{"initial", "inherit"}
@@ -454,7 +883,7 @@ func AnimationDirectionHandler(value string) bool {
 }
 
 func AnimationDurationHandler(value string) bool {
-	if Time.MatchString(value) {
+	if Time.MatchString(value) || isMath(value, mathTime) {
 		return true
 	}
 	values := []string{"initial", "inherit"}
@@ -469,7 +898,7 @@ func AnimationFillModeHandler(value string) bool {
 }
 
 func AnimationIterationCountHandler(value string) bool {
-	if Count.MatchString(value) {
+	if Count.MatchString(value) || isMath(value, mathNumber) {
 		return true
 	}
 	values := []string{"infinite", "initial", "inherit"}
@@ -487,6 +916,23 @@ func AnimationPlayStateHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+func AspectRatioHandler(value string) bool {
+	values := []string{"auto", "initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+
+	splitVals := splitFields(value)
+	switch i := slices.Index(splitVals, "auto"); i {
+	case -1:
+	case 0, len(splitVals) - 1:
+		splitVals = slices.Delete(splitVals, i, i+1)
+	default:
+		return false
+	}
+	return isRatio(strings.Join(splitVals, " "))
+}
+
 func TimingFunctionHandler(value string) bool {
 	values := []string{"linear", "ease", "ease-in", "ease-out", "ease-in-out", "step-start", "step-end", "initial", "inherit"}
 	splitVals := splitValues(value)
@@ -510,11 +956,11 @@ func BackgroundHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	newSplitVals := []string{}
 	for _, i := range splitVals {
-		if len(strings.Split(i, "/")) == 2 {
-			newSplitVals = append(newSplitVals, strings.Split(i, "/")...)
+		if len(splitTopLevel(i, "/")) == 2 {
+			newSplitVals = append(newSplitVals, splitTopLevel(i, "/")...)
 		} else {
 			newSplitVals = append(newSplitVals, i)
 		}
@@ -559,7 +1005,7 @@ func ImageHandler(value string) bool {
 	if in(splitVals, values) {
 		return true
 	}
-	return URL.MatchString(value)
+	return isURL(value) || isGradient(value)
 }
 
 func BackgroundOriginHandler(value string) bool {
@@ -584,7 +1030,7 @@ func BackgroundRepeatHandler(value string) bool {
 }
 
 func BackgroundSizeHandler(value string) bool {
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	values := []string{"auto", "cover", "contain", "initial", "inherit"}
 	if in(splitVals, values) {
 		return true
@@ -611,12 +1057,24 @@ func BorderHandler(value string) bool {
 	return recursiveCheck(splitVals, usedFunctions)
 }
 
+func BorderAxisColorHandler(value string) bool {
+	return isAxisPair(value, ColorHandler)
+}
+
+func BorderAxisStyleHandler(value string) bool {
+	return isAxisPair(value, BorderSideStyleHandler)
+}
+
+func BorderAxisWidthHandler(value string) bool {
+	return isAxisPair(value, BorderSideWidthHandler)
+}
+
 func BorderSideHandler(value string) bool {
 	values := []string{"initial", "inherit"}
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		BorderSideWidthHandler,
 		BorderSideStyleHandler,
@@ -626,7 +1084,7 @@ func BorderSideHandler(value string) bool {
 }
 
 func BorderSideRadiusHandler(value string) bool {
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	valid := true
 	for _, i := range splitVals {
 		if !LengthHandler(i) {
@@ -699,7 +1157,7 @@ func BorderImageSliceHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	if len(splitVals) > 4 {
 		return false
 	}
@@ -723,7 +1181,7 @@ func BorderRadiusHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	if len(splitVals) > 4 {
 		return false
 	}
@@ -738,7 +1196,7 @@ func BorderSpacingHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	if len(splitVals) > 2 {
 		return false
 	}
@@ -753,7 +1211,7 @@ func BorderStyleHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	if len(splitVals) > 4 {
 		return false
 	}
@@ -768,7 +1226,7 @@ func BorderWidthHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	if len(splitVals) > 4 {
 		return false
 	}
@@ -845,27 +1303,11 @@ func CaptionSideHandler(value string) bool {
 }
 
 func CaretColorHandler(value string) bool {
-	splitVals := splitValues(value)
-	if in(splitVals, colorValues) {
-		return true
-	}
-	if HexRGB.MatchString(value) {
-		return true
-	}
-	if RGB.MatchString(value) {
-		return true
-	}
-	if RGBA.MatchString(value) {
-		return true
-	}
-	if HSL.MatchString(value) {
-		return true
-	}
-	return HSLA.MatchString(value)
+	return value == "auto" || ColorHandler(value)
 }
 
 func ClearHandler(value string) bool {
-	values := []string{"none", "left", "right", "both", "initial", "inherit"}
+	values := []string{"none", "left", "right", "both", "inline-start", "inline-end", "initial", "inherit"}
 	splitVals := splitValues(value)
 	return in(splitVals, values)
 }
@@ -879,28 +1321,15 @@ func ClipHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+// ColorHandler accepts colors of CSS Color Level 4 and 5: named, system and hex
+// colors, currentcolor, rgb(), hsl(), hwb(), lab(), lch(), oklab(), oklch(),
+// color() and color-mix(), in modern and legacy syntax.
 func ColorHandler(value string) bool {
-	splitVals := splitValues(value)
-	if in(splitVals, colorValues) {
-		return true
-	}
-	if HexRGB.MatchString(value) {
-		return true
-	}
-	if RGB.MatchString(value) {
-		return true
-	}
-	if RGBA.MatchString(value) {
-		return true
-	}
-	if HSL.MatchString(value) {
-		return true
-	}
-	return HSLA.MatchString(value)
+	return value == "initial" || value == "inherit" || isColor(value)
 }
 
 func ColumnCountHandler(value string) bool {
-	if Numeric.MatchString(value) {
+	if Numeric.MatchString(value) || isMath(value, mathNumber) {
 		return true
 	}
 	values := []string{"auto", "initial", "inherit"}
@@ -928,7 +1357,7 @@ func ColumnRuleHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		ColumnRuleWidthHandler,
 		BorderSideStyleHandler,
@@ -966,7 +1395,7 @@ func ColumnsHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		ColumnWidthHandler,
 		ColumnCountHandler,
@@ -976,8 +1405,28 @@ func ColumnsHandler(value string) bool {
 
 func CursorHandler(value string) bool {
 	values := []string{"alias", "all-scroll", "auto", "cell", "context-menu", "col-resize", "copy", "crosshair", "default", "e-resize", "ew-resize", "grab", "grabbing", "help", "move", "n-resize", "ne-resize", "nesw-resize", "ns-resize", "nw-resize", "nwse-resize", "no-drop", "none", "not-allowed", "pointer", "progress", "row-resize", "s-resize", "se-resize", "sw-resize", "text", "vertical-text", "w-resize", "wait", "zoom-in", "zoom-out", "initial", "inherit"}
-	splitVals := splitValues(value)
-	return in(splitVals, values)
+	images := splitTopLevel(value, ",")
+	for _, image := range images[:len(images)-1] {
+		splitVals := splitSpaces(strings.TrimSpace(image))
+		if len(splitVals) != 1 && len(splitVals) != 3 ||
+			!isURL(splitVals[0]) {
+			return false
+		}
+		for _, i := range splitVals[1:] {
+			if !NumericDecimal.MatchString(i) {
+				return false
+			}
+		}
+	}
+	return in([]string{strings.TrimSpace(images[len(images)-1])}, values)
+}
+
+// CustomPropertyHandler is the default handler of custom properties. A custom
+// property can be used in any other property, so it allows only identifiers,
+// numbers, colors and functions, which don't load anything.
+func CustomPropertyHandler(value string) bool {
+	return CustomProperty.MatchString(value) &&
+		!CustomPropertyURL.MatchString(value)
 }
 
 func DirectionHandler(value string) bool {
@@ -1040,7 +1489,7 @@ func FlexHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		FlexGrowHandler,
 		FlexBasisHandler,
@@ -1068,7 +1517,7 @@ func FlexFlowHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		FlexDirectionHandler,
 		FlexWrapHandler,
@@ -1077,7 +1526,7 @@ func FlexFlowHandler(value string) bool {
 }
 
 func FlexGrowHandler(value string) bool {
-	if NumericDecimal.MatchString(value) {
+	if NumericDecimal.MatchString(value) || isMath(value, mathNumber) {
 		return true
 	}
 	splitVals := strings.Split(value, ";")
@@ -1092,7 +1541,7 @@ func FlexWrapHandler(value string) bool {
 }
 
 func FloatHandler(value string) bool {
-	values := []string{"none", "left", "right", "initial", "inherit"}
+	values := []string{"none", "left", "right", "inline-start", "inline-end", "initial", "inherit"}
 	splitVals := splitValues(value)
 	return in(splitVals, values)
 }
@@ -1102,7 +1551,7 @@ func FontHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	newSplitVals := []string{}
 	for _, i := range splitVals {
 		if len(strings.Split(i, "/")) == 2 {
@@ -1113,7 +1562,7 @@ func FontHandler(value string) bool {
 	}
 	usedFunctions := []func(string) bool{
 		FontStyleHandler,
-		FontVariantHandler,
+		fontVariantCSS2Handler,
 		FontWeightHandler,
 		FontSizeHandler,
 		FontFamilyHandler,
@@ -1136,6 +1585,16 @@ func FontFamilyHandler(value string) bool {
 	return true
 }
 
+func FontFeatureSettingsHandler(value string) bool {
+	values := []string{"normal", "initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	return isTagList(value, func(v string) bool {
+		return FeatureIndex.MatchString(v) || v == "on" || v == "off"
+	}, true)
+}
+
 func FontKerningHandler(value string) bool {
 	values := []string{"auto", "normal", "none"}
 	splitVals := splitValues(value)
@@ -1146,6 +1605,12 @@ func FontLanguageOverrideHandler(value string) bool {
 	return Alpha.MatchString(value)
 }
 
+func FontOpticalSizingHandler(value string) bool {
+	values := []string{"auto", "none", "initial", "inherit"}
+	splitVals := splitValues(value)
+	return in(splitVals, values)
+}
+
 func FontSizeHandler(value string) bool {
 	if LengthHandler(value) {
 		return true
@@ -1156,7 +1621,7 @@ func FontSizeHandler(value string) bool {
 }
 
 func FontSizeAdjustHandler(value string) bool {
-	if Count.MatchString(value) {
+	if Count.MatchString(value) || isMath(value, mathNumber) {
 		return true
 	}
 	values := []string{"auto", "initial", "inherit"}
@@ -1182,36 +1647,92 @@ func FontSynthesisHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+func FontVariantAlternatesHandler(value string) bool {
+	values := []string{"normal", "initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	return isKeywordGroups(value, fontVariantAlternate)
+}
+
 func FontVariantCapsHandler(value string) bool {
 	values := []string{"normal", "small-caps", "all-small-caps", "petite-caps", "all-petite-caps", "unicase", "titling-caps"}
 	splitVals := splitValues(value)
 	return in(splitVals, values)
 }
 
-func FontVariantHandler(value string) bool {
+// fontVariantCSS2Handler allows values of font-variant, which are allowed in
+// font shorthand.
+func fontVariantCSS2Handler(value string) bool {
 	values := []string{"normal", "small-caps", "initial", "inherit"}
 	splitVals := splitValues(value)
 	return in(splitVals, values)
 }
 
+func FontVariantEastAsianHandler(value string) bool {
+	values := []string{"normal", "initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	return isKeywordGroups(value, fontVariantEastAsian)
+}
+
+func FontVariantHandler(value string) bool {
+	values := []string{"normal", "none", "initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	return isKeywordGroups(value, fontVariant)
+}
+
+func FontVariantLigaturesHandler(value string) bool {
+	values := []string{"normal", "none", "initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	return isKeywordGroups(value, fontVariantLigatures)
+}
+
+func FontVariantNumericHandler(value string) bool {
+	values := []string{"normal", "initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	return isKeywordGroups(value, fontVariantNumeric)
+}
+
 func FontVariantPositionHandler(value string) bool {
 	values := []string{"normal", "sub", "super"}
 	splitVals := splitValues(value)
 	return in(splitVals, values)
 }
 
+func FontVariationSettingsHandler(value string) bool {
+	values := []string{"normal", "initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	return isTagList(value, func(v string) bool {
+		return VariationValue.MatchString(v) || isMath(v, mathNumber)
+	}, false)
+}
+
 func FontWeightHandler(value string) bool {
 	values := []string{"normal", "bold", "bolder", "lighter", "100", "200", "300", "400", "500", "600", "700", "800", "900", "initial", "inherit"}
 	splitVals := splitValues(value)
 	return in(splitVals, values)
 }
 
+func GapHandler(value string) bool {
+	return isBoxSides(value, 2, ColumnGapHandler)
+}
+
 func GridHandler(value string) bool {
 	values := []string{"none", "initial", "inherit"}
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	newSplitVals := []string{}
 	for _, i := range splitVals {
 		if i != "/" {
@@ -1275,7 +1796,8 @@ func GridColumnGapHandler(value string) bool {
 }
 
 func LengthHandler(value string) bool {
-	return Length.MatchString(value)
+	return Length.MatchString(value) ||
+		isMath(value, mathLength, mathPercent, mathNumber)
 }
 
 func LineBreakHandler(value string) bool {
@@ -1297,7 +1819,7 @@ func GridAxisStartEndHandler(value string) bool {
 }
 
 func GridGapHandler(value string) bool {
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	if len(splitVals) > 2 {
 		return false
 	}
@@ -1343,7 +1865,7 @@ func GridTemplateAreasHandler(value string) bool {
 }
 
 func GridTemplateColumnsHandler(value string) bool {
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	values := []string{"none", "auto", "max-content", "min-content", "initial", "inherit"}
 	for _, val := range splitVals {
 		if LengthHandler(val) {
@@ -1358,7 +1880,7 @@ func GridTemplateColumnsHandler(value string) bool {
 }
 
 func GridTemplateRowsHandler(value string) bool {
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	values := []string{"none", "auto", "max-content", "min-content"}
 	for _, val := range splitVals {
 		if LengthHandler(val) {
@@ -1387,6 +1909,12 @@ func HeightHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+func HyphenateCharacterHandler(value string) bool {
+	values := []string{"auto", "initial", "inherit"}
+	splitVals := splitValues(value)
+	return in(splitVals, values) || QuotedString.MatchString(value)
+}
+
 func HyphensHandler(value string) bool {
 	values := []string{"none", "manual", "auto", "initial", "inherit"}
 	splitVals := splitValues(value)
@@ -1399,6 +1927,14 @@ func ImageRenderingHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+func InsetHandler(value string) bool {
+	return isBoxSides(value, 4, SideHandler)
+}
+
+func InsetAxisHandler(value string) bool {
+	return isAxisPair(value, SideHandler)
+}
+
 func IsolationHandler(value string) bool {
 	values := []string{"auto", "isolate", "initial", "inherit"}
 	splitVals := splitValues(value)
@@ -1406,9 +1942,27 @@ func IsolationHandler(value string) bool {
 }
 
 func JustifyContentHandler(value string) bool {
-	values := []string{"flex-start", "flex-end", "center", "space-between", "space-around", "initial", "inherit"}
-	splitVals := splitValues(value)
-	return in(splitVals, values)
+	values := []string{
+		"normal", "space-between", "space-around", "space-evenly", "stretch",
+	}
+	return isAlignment(value, values, justifyContentPositions)
+}
+
+func JustifyItemsHandler(value string) bool {
+	splitVals := splitSpaces(value)
+	if slices.Contains(splitVals, "legacy") && len(splitVals) <= 2 {
+		i := slices.Index(splitVals, "legacy")
+		other := slices.Delete(slices.Clone(splitVals), i, i+1)
+		return len(other) == 0 ||
+			in(other, []string{"left", "right", "center"})
+	}
+	return isAlignment(value, []string{"normal", "stretch", "baseline"},
+		justifySelfPositions)
+}
+
+func JustifySelfHandler(value string) bool {
+	return isAlignment(value, []string{"auto", "normal", "stretch", "baseline"},
+		justifySelfPositions)
 }
 
 func LetterSpacingHandler(value string) bool {
@@ -1434,7 +1988,7 @@ func ListStyleHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		ListStyleTypeHandler,
 		ListStylePositionHandler,
@@ -1460,13 +2014,17 @@ func MarginHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		MarginSideHandler,
 	}
 	return recursiveCheck(splitVals, usedFunctions)
 }
 
+func MarginAxisHandler(value string) bool {
+	return isAxisPair(value, MarginSideHandler)
+}
+
 func MarginSideHandler(value string) bool {
 	if LengthHandler(value) {
 		return true
@@ -1500,6 +2058,18 @@ func MixBlendModeHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+func MsoHideHandler(value string) bool {
+	values := []string{"all", "none"}
+	splitVals := splitValues(value)
+	return in(splitVals, values)
+}
+
+func MsoLineHeightRuleHandler(value string) bool {
+	values := []string{"exactly", "at-least"}
+	splitVals := splitValues(value)
+	return in(splitVals, values)
+}
+
 func ObjectFitHandler(value string) bool {
 	values := []string{"fill", "contain", "cover", "none", "scale-down", "initial", "inherit"}
 	splitVals := splitValues(value)
@@ -1511,7 +2081,7 @@ func ObjectPositionHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	if len(splitVals) > 2 {
 		return false
 	}
@@ -1522,7 +2092,8 @@ func ObjectPositionHandler(value string) bool {
 }
 
 func OpacityHandler(value string) bool {
-	if Opacity.MatchString(value) {
+	if Opacity.MatchString(value) || isPercentage(value) ||
+		isMath(value, mathNumber, mathPercent) {
 		return true
 	}
 	values := []string{"initial", "inherit"}
@@ -1531,7 +2102,7 @@ func OpacityHandler(value string) bool {
 }
 
 func OrderHandler(value string) bool {
-	if Numeric.MatchString(value) {
+	if Numeric.MatchString(value) || isMath(value, mathNumber) {
 		return true
 	}
 	values := []string{"initial", "inherit"}
@@ -1544,7 +2115,7 @@ func OutlineHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		ColorHandler,
 		OutlineWidthHandler,
@@ -1596,7 +2167,7 @@ func OverflowWrapHandler(value string) bool {
 }
 
 func OrphansHandler(value string) bool {
-	return Numeric.MatchString(value)
+	return Numeric.MatchString(value) || isMath(value, mathNumber)
 }
 
 func PaddingHandler(value string) bool {
@@ -1604,7 +2175,7 @@ func PaddingHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	if len(splitVals) > 4 {
 		return false
 	}
@@ -1614,6 +2185,10 @@ func PaddingHandler(value string) bool {
 	return recursiveCheck(splitVals, usedFunctions)
 }
 
+func PaddingAxisHandler(value string) bool {
+	return isAxisPair(value, PaddingSideHandler)
+}
+
 func PaddingSideHandler(value string) bool {
 	if LengthHandler(value) {
 		return true
@@ -1649,7 +2224,7 @@ func PerspectiveOriginHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	xValues := []string{"left", "center", "right"}
 	yValues := []string{"top", "center", "bottom"}
 	if len(splitVals) > 1 {
@@ -1663,6 +2238,18 @@ func PerspectiveOriginHandler(value string) bool {
 	return false
 }
 
+func PlaceContentHandler(value string) bool {
+	return isPlacement(value, AlignContentHandler, JustifyContentHandler)
+}
+
+func PlaceItemsHandler(value string) bool {
+	return isPlacement(value, AlignItemsHandler, JustifyItemsHandler)
+}
+
+func PlaceSelfHandler(value string) bool {
+	return isPlacement(value, AlignSelfHandler, JustifySelfHandler)
+}
+
 func PointerEventsHandler(value string) bool {
 	values := []string{"auto", "none", "initial", "inherit"}
 	splitVals := splitValues(value)
@@ -1685,7 +2272,7 @@ func QuotesHandler(value string) bool {
 }
 
 func ResizeHandler(value string) bool {
-	values := []string{"none", "both", "horizontal", "vertical", "initial", "inherit"}
+	values := []string{"none", "both", "horizontal", "vertical", "block", "inline", "initial", "inherit"}
 	splitVals := splitValues(value)
 	return in(splitVals, values)
 }
@@ -1712,7 +2299,7 @@ func TableLayoutHandler(value string) bool {
 }
 
 func TextAlignHandler(value string) bool {
-	values := []string{"left", "right", "center", "justify", "initial", "inherit"}
+	values := []string{"left", "right", "center", "justify", "start", "end", "match-parent", "initial", "inherit"}
 	splitVals := splitValues(value)
 	return in(splitVals, values)
 }
@@ -1737,18 +2324,25 @@ func TextDecorationHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
+		TextDecorationLineHandler,
+		TextDecorationThicknessHandler,
 		TextDecorationStyleHandler,
 		ColorHandler,
-		TextDecorationLineHandler,
 	}
-	return recursiveCheck(splitVals, usedFunctions)
+	return unorderedCheck(splitVals, usedFunctions)
 }
 
 func TextDecorationLineHandler(value string) bool {
 	values := []string{"none", "underline", "overline", "line-through", "initial", "inherit"}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
+	return in(splitVals, values)
+}
+
+func TextDecorationSkipInkHandler(value string) bool {
+	values := []string{"auto", "none", "all", "initial", "inherit"}
+	splitVals := splitValues(value)
 	return in(splitVals, values)
 }
 
@@ -1758,6 +2352,49 @@ func TextDecorationStyleHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+func TextDecorationThicknessHandler(value string) bool {
+	if LengthHandler(value) {
+		return true
+	}
+	values := []string{"auto", "from-font", "initial", "inherit"}
+	splitVals := splitValues(value)
+	return in(splitVals, values)
+}
+
+func TextEmphasisHandler(value string) bool {
+	values := []string{"initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	splitVals := splitSpaces(value)
+	usedFunctions := []func(string) bool{
+		TextEmphasisStyleHandler,
+		ColorHandler,
+	}
+	return unorderedCheck(splitVals, usedFunctions)
+}
+
+func TextEmphasisPositionHandler(value string) bool {
+	values := []string{"initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	splitVals := splitSpaces(value)
+	return isKeywordGroups(value, [][]string{{"over", "under"}, {"left", "right"}}) &&
+		(slices.Contains(splitVals, "over") || slices.Contains(splitVals, "under"))
+}
+
+func TextEmphasisStyleHandler(value string) bool {
+	values := []string{"none", "initial", "inherit"}
+	if in([]string{value}, values) || QuotedString.MatchString(value) {
+		return true
+	}
+	return isKeywordGroups(value, [][]string{
+		{"filled", "open"},
+		{"dot", "circle", "double-circle", "triangle", "sesame"},
+	})
+}
+
 func TextIndentHandler(value string) bool {
 	if LengthHandler(value) {
 		return true
@@ -1821,6 +2458,50 @@ func TextTransformHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+func TextUnderlineOffsetHandler(value string) bool {
+	if LengthHandler(value) {
+		return true
+	}
+	values := []string{"auto", "initial", "inherit"}
+	splitVals := splitValues(value)
+	return in(splitVals, values)
+}
+
+func TextUnderlinePositionHandler(value string) bool {
+	values := []string{"auto", "initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	return isKeywordGroups(value, [][]string{
+		{"from-font", "under"}, {"left", "right"},
+	})
+}
+
+func TextWrapHandler(value string) bool {
+	values := []string{"initial", "inherit"}
+	if in([]string{value}, values) {
+		return true
+	}
+	splitVals := splitSpaces(value)
+	usedFunctions := []func(string) bool{
+		TextWrapModeHandler,
+		TextWrapStyleHandler,
+	}
+	return unorderedCheck(splitVals, usedFunctions)
+}
+
+func TextWrapModeHandler(value string) bool {
+	values := []string{"wrap", "nowrap", "initial", "inherit"}
+	splitVals := splitValues(value)
+	return in(splitVals, values)
+}
+
+func TextWrapStyleHandler(value string) bool {
+	values := []string{"auto", "balance", "stable", "pretty", "initial", "inherit"}
+	splitVals := splitValues(value)
+	return in(splitVals, values)
+}
+
 func TransformHandler(value string) bool {
 	values := []string{"none", "initial", "inherit"}
 	if in([]string{value}, values) {
@@ -1847,21 +2528,27 @@ func TransformHandler(value string) bool {
 	if Rotate.MatchString(value) {
 		return true
 	}
+	if m := RotateAngle.FindStringSubmatch(value); m != nil && AngleHandler(m[2]) {
+		return true
+	}
 	if Rotate3D.MatchString(value) {
 		return true
 	}
-	subValue = string(Skew.ReplaceAll([]byte(value), []byte{}))
-	subValue = strings.TrimSuffix(subValue, ")")
-	trimValue = strings.Split(subValue, ",")
-	valid = true
-	for _, i := range trimValue {
-		if !LengthHandler(strings.TrimSpace(i)) {
-			valid = false
-			break
+	if Skew.MatchString(value) {
+		subValue = string(Skew.ReplaceAll([]byte(value), []byte{}))
+		subValue = strings.TrimSuffix(subValue, ")")
+		trimValue = strings.Split(subValue, ",")
+		valid = len(trimValue) == 1 ||
+			len(trimValue) == 2 && strings.HasPrefix(value, "skew(")
+		for _, i := range trimValue {
+			if !AngleHandler(strings.TrimSpace(i)) {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			return true
 		}
-	}
-	if valid {
-		return true
 	}
 	subValue = string(Perspective.ReplaceAll([]byte(value), []byte{}))
 	subValue = strings.TrimSuffix(subValue, ")")
@@ -1873,7 +2560,7 @@ func TransformOriginHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	xValues := []string{"left", "center", "right"}
 	yValues := []string{"top", "center", "bottom"}
 
@@ -1911,7 +2598,7 @@ func TransitionHandler(value string) bool {
 	if in([]string{value}, values) {
 		return true
 	}
-	splitVals := strings.Split(value, " ")
+	splitVals := splitSpaces(value)
 	usedFunctions := []func(string) bool{
 		TransitionPropertyHandler,
 		TransitionDurationHandler,
@@ -1923,7 +2610,7 @@ func TransitionHandler(value string) bool {
 }
 
 func TransitionDelayHandler(value string) bool {
-	if Time.MatchString(value) {
+	if Time.MatchString(value) || isMath(value, mathTime) {
 		return true
 	}
 	values := []string{"initial", "inherit"}
@@ -1932,7 +2619,7 @@ func TransitionDelayHandler(value string) bool {
 }
 
 func TransitionDurationHandler(value string) bool {
-	if Time.MatchString(value) {
+	if Time.MatchString(value) || isMath(value, mathTime) {
 		return true
 	}
 	values := []string{"initial", "inherit"}
@@ -1976,6 +2663,15 @@ func VisiblityHandler(value string) bool {
 	return in(splitVals, values)
 }
 
+func WhiteSpaceCollapseHandler(value string) bool {
+	values := []string{
+		"collapse", "discard", "preserve", "preserve-breaks", "preserve-spaces",
+		"break-spaces", "initial", "inherit",
+	}
+	splitVals := splitValues(value)
+	return in(splitVals, values)
+}
+
 func WhiteSpaceHandler(value string) bool {
 	values := []string{"normal", "nowrap", "pre", "pre-line", "pre-wrap", "initial", "inherit"}
 	splitVals := splitValues(value)
@@ -2019,7 +2715,7 @@ func WritingModeHandler(value string) bool {
 }
 
 func ZIndexHandler(value string) bool {
-	if ZIndex.MatchString(value) {
+	if ZIndex.MatchString(value) || isMath(value, mathNumber) {
 		return true
 	}
 	values := []string{"auto", "initial", "inherit"}
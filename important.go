@@ -0,0 +1,98 @@
+package css
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ImportantMode describes how declarations with !important are handled.
+type ImportantMode int
+
+const (
+	// ImportantDefault uses the mode of the policy for style policies, and
+	// ImportantStrip for the policy.
+	ImportantDefault ImportantMode = iota
+
+	// ImportantStrip removes !important and emits the declaration without it.
+	ImportantStrip
+
+	// ImportantPreserve emits the declaration with !important.
+	ImportantPreserve
+
+	// ImportantReject removes declarations with !important.
+	ImportantReject
+)
+
+var importantSuffix = regexp.MustCompile(`(?i)\s*!\s*important\s*$`)
+
+var importantModeNames = map[ImportantMode]string{
+	ImportantDefault:  "default",
+	ImportantStrip:    "strip",
+	ImportantPreserve: "preserve",
+	ImportantReject:   "reject",
+}
+
+func (self ImportantMode) String() string {
+	if s, ok := importantModeNames[self]; ok {
+		return s
+	}
+	return "ImportantMode(" + strconv.Itoa(int(self)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (self ImportantMode) MarshalText() ([]byte, error) {
+	if s, ok := importantModeNames[self]; ok {
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("css: unknown important mode %d", int(self))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (self *ImportantMode) UnmarshalText(text []byte) error {
+	for mode, s := range importantModeNames {
+		if s == string(text) {
+			*self = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("css: unknown important mode %q", text)
+}
+
+// Important sets how declarations with !important are handled by the policy
+// and returns the updated policy. By default !important is stripped, so user
+// content can't out-rank site CSS.
+func (self *Policy) Important(mode ImportantMode) *Policy {
+	self.important = mode
+	return self
+}
+
+// Important sets how declarations with !important are handled by a nascent
+// style policy and returns the style policy. It takes precedence over the mode
+// of the policy, like
+//
+//	p.AllowStyles("color").Important(css.ImportantPreserve).OnElements("span")
+func (self *PolicyBuilder) Important(mode ImportantMode) *PolicyBuilder {
+	self.important = mode
+	return self
+}
+
+// importantMode returns how the style policy handles !important.
+func (self *Policy) importantMode(sp *stylePolicy) ImportantMode {
+	switch {
+	case sp.important != ImportantDefault:
+		return sp.important
+	case self.important != ImportantDefault:
+		return self.important
+	}
+	return ImportantStrip
+}
+
+// cutImportant returns value without !important and true, if value ends with
+// it.
+func cutImportant(value string) (string, bool) {
+	if loc := importantSuffix.FindStringIndex(value); loc != nil {
+		return value[:loc[0]], true
+	}
+	return value, false
+}
@@ -0,0 +1,93 @@
+package css
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportant(t *testing.T) {
+	tests := []struct {
+		name     string
+		policyFn func(p *Policy)
+		in       string
+		expected string
+	}{
+		{
+			name: "default",
+			policyFn: func(p *Policy) {
+				p.AllowStyles("color", "width").Globally()
+			},
+			in:       "color: red !important; width: 1px ! IMPORTANT; color: blue",
+			expected: "color: red; width: 1px; color: blue",
+		},
+		{
+			name: "ImportantPreserve",
+			policyFn: func(p *Policy) {
+				p.Important(ImportantPreserve)
+				p.AllowStyles("color").Globally()
+			},
+			in:       "color: red !important; color: blue",
+			expected: "color: red !important; color: blue",
+		},
+		{
+			name: "ImportantReject",
+			policyFn: func(p *Policy) {
+				p.Important(ImportantReject)
+				p.AllowStyles("color", "margin").Globally()
+			},
+			in:       "color: red !important; color: blue; margin: 0 !important",
+			expected: "color: blue",
+		},
+		{
+			name: "per property",
+			policyFn: func(p *Policy) {
+				p.Important(ImportantReject)
+				p.AllowStyles("color").Important(ImportantPreserve).
+					OnElements("div")
+				p.AllowStyles("margin").Important(ImportantStrip).Globally()
+			},
+			in:       "color: red !important; margin: 0 !important",
+			expected: "color: red !important; margin: 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPolicy()
+			tt.policyFn(p)
+			require.NoError(t, p.Err())
+			assert.Equal(t, tt.expected, p.Sanitize("div", tt.in))
+
+			b, err := json.Marshal(p)
+			require.NoError(t, err)
+			p2 := NewPolicy()
+			require.NoError(t, json.Unmarshal(b, p2))
+			assert.Equal(t, tt.expected, p2.Sanitize("div", tt.in))
+		})
+	}
+
+	p := NewPolicy().Important(ImportantReject)
+	p.AllowStyles("color").Globally()
+	_, rejected := p.SanitizeWithReport("div", "color: red !important")
+	assert.Equal(t, []Rejection{
+		{Property: "color", Value: "red", Reason: RejectImportant},
+	}, rejected)
+
+	e := p.Explain("div", "color", "red !important")
+	assert.Equal(t, "red", e.Normalized)
+	assert.Equal(t, RejectImportant, e.Reason)
+}
+
+func TestImportant_Merge(t *testing.T) {
+	p := NewPolicy().Merge(NewPolicy().Important(ImportantPreserve))
+	p.AllowStyles("color").Globally()
+	assert.Equal(t, "color: red !important",
+		p.Sanitize("div", "color: red !important"))
+
+	p = NewPolicy().Important(ImportantReject).
+		Merge(NewPolicy().Important(ImportantPreserve))
+	assert.Equal(t, ImportantReject, p.important)
+}
@@ -0,0 +1,243 @@
+package css
+
+import (
+	"cmp"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// Scope describes which HTML elements a style policy is bound to.
+type Scope int
+
+const (
+	// ScopeElement is a style policy bound by PolicyBuilder.OnElements.
+	ScopeElement Scope = iota + 1
+
+	// ScopeMatching is a style policy bound by
+	// PolicyBuilder.OnElementsMatching.
+	ScopeMatching
+
+	// ScopeGlobal is a style policy bound by PolicyBuilder.Globally.
+	ScopeGlobal
+)
+
+var scopeNames = map[Scope]string{
+	ScopeElement:  "element",
+	ScopeMatching: "matching",
+	ScopeGlobal:   "global",
+}
+
+func (self Scope) String() string {
+	if s, ok := scopeNames[self]; ok {
+		return s
+	}
+	return "Scope(" + strconv.Itoa(int(self)) + ")"
+}
+
+// ValidatorKind describes how a validator of a style policy checks values.
+type ValidatorKind int
+
+const (
+	// ValidatorDefault is the default handler of the property.
+	ValidatorDefault ValidatorKind = iota + 1
+
+	// ValidatorHandler is a handler set by PolicyBuilder.MatchingHandler or
+	// PolicyBuilder.MatchingNamedHandler.
+	ValidatorHandler
+
+	// ValidatorEnum is a list of values set by PolicyBuilder.MatchingEnum.
+	ValidatorEnum
+
+	// ValidatorRegexp is a regexp set by PolicyBuilder.Matching.
+	ValidatorRegexp
+
+	// ValidatorRange is a range set by PolicyBuilder.MatchingRange.
+	ValidatorRange
+)
+
+var validatorKindNames = map[ValidatorKind]string{
+	ValidatorDefault: "default handler",
+	ValidatorHandler: "handler",
+	ValidatorEnum:    "enum",
+	ValidatorRegexp:  "regexp",
+	ValidatorRange:   "range",
+}
+
+func (self ValidatorKind) String() string {
+	if s, ok := validatorKindNames[self]; ok {
+		return s
+	}
+	return "ValidatorKind(" + strconv.Itoa(int(self)) + ")"
+}
+
+// Validator describes a single validator of a style policy.
+type Validator struct {
+	Kind ValidatorKind
+
+	// Name is the name of the handler for ValidatorDefault and
+	// ValidatorHandler. It's empty for handlers without name.
+	Name string
+
+	// Enum is the list of allowed values for ValidatorEnum.
+	Enum []string
+
+	// Regexp is the source of regexp for ValidatorRegexp.
+	Regexp string
+
+	// Range is the range of values for ValidatorRange.
+	Range *Range
+}
+
+// StyleRule describes a single style policy or deny rule of a property.
+type StyleRule struct {
+	// Property is the name of the property, or "--" for custom properties.
+	Property string
+	Scope    Scope
+
+	// PropertyPattern is the source of regexp of names of custom properties the
+	// style policy applies to.
+	PropertyPattern string
+
+	// Pattern is the source of element regexp for ScopeMatching.
+	Pattern string
+
+	// Validators of the style policy. A deny rule without validators denies any
+	// value.
+	Validators []Validator
+
+	// All is true if all validators must allow a value, otherwise any of them.
+	All bool
+
+	// Transform is true if the style policy has a transformer set by
+	// PolicyBuilder.MatchingTransform.
+	Transform bool
+
+	// Prefixes are vendor prefixes allowed by PolicyBuilder.AllowPrefixes. It's
+	// nil if all recognised prefixes are allowed.
+	Prefixes []string
+
+	// Units are units of lengths allowed by PolicyBuilder.AllowUnits. It's nil
+	// if units of the policy are used.
+	Units []string
+
+	// Important is the mode set by PolicyBuilder.Important.
+	Important ImportantMode
+}
+
+// Elements returns sorted names of HTML elements, which have style policies
+// bound by PolicyBuilder.OnElements.
+func (self *Policy) Elements() []string {
+	return slices.Sorted(maps.Keys(self.elsAndStyles))
+}
+
+// ElementPatterns returns sorted sources of element regexps, which have style
+// policies bound by PolicyBuilder.OnElementsMatching.
+func (self *Policy) ElementPatterns() []string {
+	matchers := sortedMatchers(self.elsMatchingAndStyles)
+	patterns := make([]string, len(matchers))
+	for i, m := range matchers {
+		patterns[i] = m.regexp.String()
+	}
+	return slices.Compact(patterns)
+}
+
+// AllowedStyles returns all style policies applied to elementName, sorted by
+// property and scope. Like Sanitize, it returns policies bound by
+// OnElementsMatching only if the element has no policies bound by OnElements.
+func (self *Policy) AllowedStyles(elementName string) []StyleRule {
+	rules := self.styleRules(elementName, self.elsAndStyles,
+		self.elsMatchingAndStyles, self.globalStyles)
+	if len(self.elsAndStyles[elementName]) > 0 {
+		rules = slices.DeleteFunc(rules, func(r StyleRule) bool {
+			return r.Scope == ScopeMatching
+		})
+	}
+	return rules
+}
+
+// DeniedStyles returns all deny rules applied to elementName, sorted by
+// property and scope.
+func (self *Policy) DeniedStyles(elementName string) []StyleRule {
+	return self.styleRules(elementName, self.elsAndDenies,
+		self.elsMatchingAndDenies, self.globalDenies)
+}
+
+func (self *Policy) styleRules(elementName string,
+	els map[string]map[string][]stylePolicy,
+	elsMatching map[*regexp.Regexp]map[string][]stylePolicy,
+	global map[string][]stylePolicy,
+) []StyleRule {
+	var rules []StyleRule
+	add := func(scope Scope, pattern string, styles map[string][]stylePolicy) {
+		for property, spl := range styles {
+			for i := range spl {
+				var propertyPattern string
+				if spl[i].propertyRegexp != nil {
+					propertyPattern = spl[i].propertyRegexp.String()
+				}
+				rules = append(rules, StyleRule{
+					Property:        property,
+					PropertyPattern: propertyPattern,
+					Scope:           scope,
+					Pattern:         pattern,
+					Validators:      self.validators(&spl[i]),
+					All:             spl[i].matchAll,
+					Transform:       spl[i].transform != nil,
+					Prefixes:        slices.Clone(spl[i].prefixes),
+					Units:           slices.Clone(spl[i].units),
+					Important:       spl[i].important,
+				})
+			}
+		}
+	}
+
+	add(ScopeElement, "", els[elementName])
+	for _, m := range sortedMatchers(elsMatching) {
+		if m.regexp.MatchString(elementName) {
+			add(ScopeMatching, m.regexp.String(), m.styles)
+		}
+	}
+	add(ScopeGlobal, "", global)
+
+	slices.SortStableFunc(rules, func(a, b StyleRule) int {
+		return cmp.Or(cmp.Compare(a.Property, b.Property),
+			cmp.Compare(a.Scope, b.Scope), cmp.Compare(a.Pattern, b.Pattern))
+	})
+	return rules
+}
+
+// validators returns all validators of the style policy, in order they're
+// checked.
+func (self *Policy) validators(sp *stylePolicy) []Validator {
+	var validators []Validator
+	if sp.handler != nil {
+		v := Validator{Kind: ValidatorHandler, Name: sp.handlerName}
+		if _, ok := self.handlers[v.Name]; sp.defaultHandler || !ok && v.Name != "" {
+			v.Kind = ValidatorDefault
+		}
+		validators = append(validators, v)
+	}
+
+	if len(sp.enum) > 0 {
+		validators = append(validators, Validator{
+			Kind: ValidatorEnum,
+			Enum: slices.Clone(sp.enum),
+		})
+	}
+
+	if sp.regexp != nil {
+		validators = append(validators, Validator{
+			Kind:   ValidatorRegexp,
+			Regexp: sp.regexp.String(),
+		})
+	}
+
+	if sp.valueRange != nil {
+		r := *sp.valueRange
+		r.Units = slices.Clone(r.Units)
+		validators = append(validators, Validator{Kind: ValidatorRange, Range: &r})
+	}
+	return validators
+}
@@ -0,0 +1,76 @@
+package css
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_introspection(t *testing.T) {
+	p := NewPolicy().RegisterHandlers(map[string]func(string) bool{
+		"true": trueHandler,
+	})
+	p.AllowStyles("color").MatchingEnum("red", "blue").OnElements("span", "p")
+	p.AllowStyles("text-align").
+		OnElementsMatching(regexp.MustCompile(`^h[1-6]$`))
+	p.AllowStyles("mystyle").MatchingNamedHandler("true").
+		OnElementsMatching(regexp.MustCompile(`^h1$`))
+	p.AllowStyles("margin").MatchingDefaultHandler().
+		Matching(regexp.MustCompile(`^0$`)).MatchingAll().Globally()
+	p.DisallowStyles("margin").OnElements("p")
+
+	assert.Equal(t, []string{"p", "span"}, p.Elements())
+	assert.Equal(t, []string{"^h1$", "^h[1-6]$"}, p.ElementPatterns())
+
+	assert.Equal(t, []StyleRule{
+		{
+			Property: "color",
+			Scope:    ScopeElement,
+			Validators: []Validator{
+				{Kind: ValidatorEnum, Enum: []string{"red", "blue"}},
+			},
+		},
+		{
+			Property: "margin",
+			Scope:    ScopeGlobal,
+			Validators: []Validator{
+				{Kind: ValidatorDefault, Name: "margin"},
+				{Kind: ValidatorRegexp, Regexp: "^0$"},
+			},
+			All: true,
+		},
+	}, p.AllowedStyles("p"))
+
+	assert.Equal(t, []StyleRule{
+		{
+			Property: "margin",
+			Scope:    ScopeGlobal,
+			Validators: []Validator{
+				{Kind: ValidatorDefault, Name: "margin"},
+				{Kind: ValidatorRegexp, Regexp: "^0$"},
+			},
+			All: true,
+		},
+		{
+			Property:   "mystyle",
+			Scope:      ScopeMatching,
+			Pattern:    "^h1$",
+			Validators: []Validator{{Kind: ValidatorHandler, Name: "true"}},
+		},
+		{
+			Property:   "text-align",
+			Scope:      ScopeMatching,
+			Pattern:    "^h[1-6]$",
+			Validators: []Validator{{Kind: ValidatorDefault, Name: "text-align"}},
+		},
+	}, p.AllowedStyles("h1"))
+
+	assert.Equal(t, []StyleRule{
+		{Property: "margin", Scope: ScopeElement},
+	}, p.DeniedStyles("p"))
+	assert.Empty(t, p.DeniedStyles("span"))
+
+	assert.Equal(t, "matching", ScopeMatching.String())
+	assert.Equal(t, "default handler", ValidatorDefault.String())
+}
@@ -0,0 +1,386 @@
+package css
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// ErrNotSerializable is returned by Policy.MarshalJSON when the policy contains
+// something, which can't be represented in JSON, like a handler without name.
+var ErrNotSerializable = errors.New("css: not serializable")
+
+// jsonPolicy is JSON representation of Policy, like
+//
+//	{
+//	  "elements": {"span": {"color": [{"default": true}]}},
+//	  "matching": {"^h[1-6]$": {"text-align": [{"enum": ["left", "right"]}]}},
+//	  "global": {"color": [{"regexp": "^#[0-9a-f]{6}$"}]},
+//	  "deny": {"global": {"position": [{"enum": ["fixed"]}]}}
+//	}
+type jsonPolicy struct {
+	jsonScopes
+
+	Deny *jsonScopes `json:"deny,omitempty"`
+
+	VendorPrefixes *[]string     `json:"vendor_prefixes,omitempty"`
+	PrefixOutput   PrefixMode    `json:"prefix_output,omitempty"`
+	Important      ImportantMode `json:"important,omitempty"`
+	Duplicates     DuplicateMode `json:"duplicates,omitempty"`
+	Limits         *Limits       `json:"limits,omitempty"`
+
+	UnitFactors map[string]float64 `json:"unit_factors,omitempty"`
+	Units       *[]string          `json:"units,omitempty"`
+	Variables   *[]string          `json:"variables,omitempty"`
+	URLs        *URLPolicy         `json:"urls,omitempty"`
+}
+
+// jsonRange is JSON representation of Range. Infinite bounds are omitted.
+type jsonRange struct {
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+	Units []string `json:"units,omitempty"`
+}
+
+func newJSONRange(r *Range) *jsonRange {
+	jr := &jsonRange{Units: r.Units}
+	if !math.IsInf(r.Min, 0) {
+		jr.Min = &r.Min
+	}
+	if !math.IsInf(r.Max, 0) {
+		jr.Max = &r.Max
+	}
+	return jr
+}
+
+func (self *jsonRange) valueRange() *Range {
+	r := &Range{Min: math.Inf(-1), Max: math.Inf(1)}
+	if self.Min != nil {
+		r.Min = *self.Min
+	}
+	if self.Max != nil {
+		r.Max = *self.Max
+	}
+	for _, unit := range self.Units {
+		r.Units = append(r.Units, strings.ToLower(unit))
+	}
+	return r
+}
+
+type jsonScopes struct {
+	Elements map[string]jsonStyles `json:"elements,omitempty"`
+	Matching map[string]jsonStyles `json:"matching,omitempty"`
+	Global   jsonStyles            `json:"global,omitempty"`
+}
+
+type jsonStyles map[string][]jsonStylePolicy
+
+type jsonStylePolicy struct {
+	Property  string        `json:"property,omitempty"`
+	Handler   string        `json:"handler,omitempty"`
+	Default   bool          `json:"default,omitempty"`
+	Enum      []string      `json:"enum,omitempty"`
+	Regexp    string        `json:"regexp,omitempty"`
+	Range     *jsonRange    `json:"range,omitempty"`
+	All       bool          `json:"all,omitempty"`
+	Prefixes  *[]string     `json:"prefixes,omitempty"`
+	Units     *[]string     `json:"units,omitempty"`
+	Important ImportantMode `json:"important,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. Handlers are referenced by name, so
+// only handlers registered by RegisterHandlers and default handlers can be
+// marshaled. It returns ErrNotSerializable for handlers without name, for
+// transformers and for URL validators.
+func (self *Policy) MarshalJSON() ([]byte, error) {
+	var jp jsonPolicy
+	err := jp.jsonScopes.marshal(self.elsAndStyles, self.elsMatchingAndStyles,
+		self.globalStyles)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(self.elsAndDenies) > 0 || len(self.elsMatchingAndDenies) > 0 ||
+		len(self.globalDenies) > 0 {
+		jp.Deny = new(jsonScopes)
+		err := jp.Deny.marshal(self.elsAndDenies, self.elsMatchingAndDenies,
+			self.globalDenies)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if self.prefixes != nil {
+		jp.VendorPrefixes = &self.prefixes
+	}
+	jp.PrefixOutput, jp.Important = self.prefixOutput, self.important
+	jp.Duplicates = self.duplicates
+	if self.limits != DefaultLimits() {
+		jp.Limits = &self.limits
+	}
+	jp.UnitFactors = self.unitFactors
+	if self.units != nil {
+		jp.Units = &self.units
+	}
+	if self.variables != nil {
+		jp.Variables = &self.variables
+	}
+	if self.urlValidator != nil {
+		return nil, fmt.Errorf("%w: URL validator", ErrNotSerializable)
+	}
+	jp.URLs = self.urls
+
+	b, err := json.Marshal(&jp)
+	if err != nil {
+		return nil, fmt.Errorf("css: marshal policy: %w", err)
+	}
+	return b, nil
+}
+
+func (self *jsonScopes) marshal(els map[string]map[string][]stylePolicy,
+	elsMatching map[*regexp.Regexp]map[string][]stylePolicy,
+	global map[string][]stylePolicy,
+) (err error) {
+	if len(els) > 0 {
+		self.Elements = make(map[string]jsonStyles, len(els))
+		for elementName, styles := range els {
+			if self.Elements[elementName], err = marshalStyles(nil,
+				styles); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(elsMatching) > 0 {
+		self.Matching = make(map[string]jsonStyles, len(elsMatching))
+		for _, m := range sortedMatchers(elsMatching) {
+			source := m.regexp.String()
+			if self.Matching[source], err = marshalStyles(self.Matching[source],
+				m.styles); err != nil {
+				return err
+			}
+		}
+	}
+
+	self.Global, err = marshalStyles(nil, global)
+	return err
+}
+
+func marshalStyles(to jsonStyles, styles map[string][]stylePolicy,
+) (jsonStyles, error) {
+	if len(styles) == 0 {
+		return to, nil
+	} else if to == nil {
+		to = make(jsonStyles, len(styles))
+	}
+
+	for property, spl := range styles {
+		for i := range spl {
+			sp := &spl[i]
+			if sp.handler != nil && sp.handlerName == "" {
+				return nil, fmt.Errorf("%w: handler of %q has no name",
+					ErrNotSerializable, property)
+			} else if sp.transform != nil {
+				return nil, fmt.Errorf("%w: transformer of %q",
+					ErrNotSerializable, property)
+			}
+
+			jsp := jsonStylePolicy{
+				Enum: sp.enum,
+				All:  sp.matchAll,
+
+				Important: sp.important,
+			}
+			if sp.defaultHandler {
+				jsp.Default = true
+			} else {
+				jsp.Handler = sp.handlerName
+			}
+			if sp.prefixes != nil {
+				jsp.Prefixes = &sp.prefixes
+			}
+			if sp.units != nil {
+				jsp.Units = &sp.units
+			}
+			if sp.regexp != nil {
+				jsp.Regexp = sp.regexp.String()
+			}
+			if sp.valueRange != nil {
+				jsp.Range = newJSONRange(sp.valueRange)
+			}
+			if sp.propertyRegexp != nil {
+				jsp.Property = sp.propertyRegexp.String()
+			}
+			to[property] = append(to[property], jsp)
+		}
+	}
+	return to, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It replaces all style policies and
+// deny rules of the policy by style policies from JSON. Handlers are resolved by
+// name, using handlers registered by RegisterHandlers or default handlers, so
+// register custom handlers before unmarshaling, like
+//
+//	p := css.NewPolicy().RegisterHandlers(map[string]func(string) bool{
+//	  "my-handler": myHandler,
+//	})
+//	err := json.Unmarshal(b, p)
+//
+// A style policy with "default": true, or without any of handler, enum, regexp
+// and range, uses the default handler of its property. It returns
+// ErrUnknownHandler for a handler name, which is neither registered nor a
+// property name with a default handler. Limits missing from JSON are
+// DefaultLimits.
+func (self *Policy) UnmarshalJSON(b []byte) error {
+	// limits missing from JSON keep their defaults, because zero means no limit
+	limits := DefaultLimits()
+	jp := jsonPolicy{Limits: &limits}
+	if err := json.Unmarshal(b, &jp); err != nil {
+		return fmt.Errorf("css: unmarshal policy: %w", err)
+	}
+
+	p := NewPolicy()
+	p.handlers = self.handlers
+	err := p.unmarshalScopes(&jp.jsonScopes, p.elsAndStyles,
+		p.elsMatchingAndStyles, p.globalStyles, false)
+	if err != nil {
+		return err
+	}
+
+	if jp.Deny != nil {
+		err := p.unmarshalScopes(jp.Deny, p.elsAndDenies, p.elsMatchingAndDenies,
+			p.globalDenies, true)
+		if err != nil {
+			return err
+		}
+	}
+	p.sortMatchers()
+
+	if jp.VendorPrefixes != nil {
+		p.VendorPrefixes(*jp.VendorPrefixes...)
+	}
+	p.prefixOutput, p.important = jp.PrefixOutput, jp.Important
+	p.duplicates = jp.Duplicates
+	if jp.Limits != nil {
+		p.limits = *jp.Limits
+	}
+	if jp.UnitFactors != nil {
+		p.UnitFactors(jp.UnitFactors)
+	}
+	if jp.Units != nil {
+		p.AllowUnits(*jp.Units...)
+	}
+	if jp.Variables != nil {
+		p.AllowVariables(*jp.Variables...)
+	}
+	if jp.URLs != nil {
+		p.AllowURLs(*jp.URLs)
+	}
+
+	*self = *p
+	return nil
+}
+
+func (self *Policy) unmarshalScopes(scopes *jsonScopes,
+	els map[string]map[string][]stylePolicy,
+	elsMatching map[*regexp.Regexp]map[string][]stylePolicy,
+	global map[string][]stylePolicy, deny bool,
+) error {
+	for elementName, styles := range scopes.Elements {
+		sps, err := self.unmarshalStyles(styles, deny)
+		if err != nil {
+			return err
+		}
+		els[strings.ToLower(elementName)] = sps
+	}
+
+	for source, styles := range scopes.Matching {
+		regex, err := regexp.Compile(source)
+		if err != nil {
+			return fmt.Errorf("css: element regexp %q: %w", source, err)
+		}
+
+		sps, err := self.unmarshalStyles(styles, deny)
+		if err != nil {
+			return err
+		}
+		elsMatching[regex] = sps
+	}
+
+	sps, err := self.unmarshalStyles(scopes.Global, deny)
+	if err != nil {
+		return err
+	}
+	maps.Copy(global, sps)
+	return nil
+}
+
+func (self *Policy) unmarshalStyles(styles jsonStyles, deny bool,
+) (map[string][]stylePolicy, error) {
+	sps := make(map[string][]stylePolicy, len(styles))
+	for property, jspl := range styles {
+		property = strings.ToLower(property)
+		key := styleKey(property)
+		spl := make([]stylePolicy, len(jspl))
+		for i, jsp := range jspl {
+			sp := &spl[i]
+			sp.handlerName, sp.enum, sp.matchAll = jsp.Handler, jsp.Enum, jsp.All
+			if !deny {
+				if jsp.Prefixes != nil {
+					sp.prefixes = lowerStrings(*jsp.Prefixes)
+				}
+				if jsp.Units != nil {
+					sp.units = lowerStrings(*jsp.Units)
+				}
+				sp.important = jsp.Important
+			}
+			switch {
+			case jsp.Default && jsp.Handler != "":
+				return nil, fmt.Errorf("css: both handler %q and default handler of %q",
+					jsp.Handler, property)
+			case jsp.Default || jsp.Handler == "" && len(sp.enum) == 0 &&
+				jsp.Regexp == "" && jsp.Range == nil && !deny:
+				sp.handler, sp.handlerName = GetDefaultHandler(key), key
+				sp.defaultHandler = true
+			case jsp.Handler != "":
+				handler, ok := self.namedHandler(jsp.Handler)
+				if !ok {
+					return nil, fmt.Errorf("%w: %q of %q", ErrUnknownHandler, jsp.Handler,
+						property)
+				}
+				sp.handler = handler
+			}
+
+			if jsp.Regexp != "" {
+				regex, err := regexp.Compile(jsp.Regexp)
+				if err != nil {
+					return nil, fmt.Errorf("css: regexp of %q: %w", property, err)
+				}
+				sp.regexp = regex
+			}
+
+			if jsp.Range != nil {
+				sp.valueRange = jsp.Range.valueRange()
+			}
+
+			if jsp.Property != "" {
+				regex, err := regexp.Compile(jsp.Property)
+				if err != nil {
+					return nil, fmt.Errorf("css: property regexp of %q: %w", property,
+						err)
+				}
+				sp.propertyRegexp = regex
+			} else if property != key {
+				sp.propertyRegexp = regexp.MustCompile(
+					"^" + regexp.QuoteMeta(property) + "$")
+			}
+		}
+		sps[key] = append(sps[key], spl...)
+	}
+	return sps, nil
+}
@@ -0,0 +1,123 @@
+package css
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_JSON(t *testing.T) {
+	handlers := map[string]func(string) bool{"true": trueHandler}
+
+	p := NewPolicy().RegisterHandlers(handlers)
+	p.AllowStyles("text-decoration").
+		MatchingEnum("underline", "line-through", "none").OnElements("span")
+	p.AllowStyles("color").
+		Matching(regexp.MustCompile(`^#[0-9a-f]{3}$`)).
+		MatchingEnum("red").MatchingAny().
+		Globally()
+	p.AllowStyles("background-color").MatchingDefaultHandler().
+		Matching(regexp.MustCompile(`^#`)).MatchingAll().Globally()
+	p.AllowStyles("margin", "padding").
+		OnElementsMatching(regexp.MustCompile(`^h[1-6]$`))
+	p.AllowStyles("mystyle").MatchingNamedHandler("true").OnElements("p")
+	p.AllowStyles("position").Globally()
+	p.DisallowStyles("position").MatchingEnum("fixed").Globally()
+	p.DisallowStyles("margin").OnElementsMatching(regexp.MustCompile(`^h1$`))
+	require.NoError(t, p.Err())
+
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+
+	p2 := NewPolicy().RegisterHandlers(handlers)
+	require.NoError(t, json.Unmarshal(b, p2))
+
+	b2, err := json.Marshal(p2)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(b), string(b2))
+
+	tests := []struct {
+		element, in string
+	}{
+		{"span", "text-decoration: underline; color: red; color: #fff; color: blue"},
+		{"p", "background-color: #fff; background-color: red; mystyle: any"},
+		{"h1", "margin: 0; padding: 1px"},
+		{"h2", "margin: 0; padding: 1px"},
+		{"div", "position: fixed; position: relative; mystyle: any"},
+	}
+
+	for i, tt := range tests {
+		assert.Equal(t, p.Sanitize(tt.element, tt.in),
+			p2.Sanitize(tt.element, tt.in), "test %v", i)
+	}
+}
+
+func TestPolicy_UnmarshalJSON(t *testing.T) {
+	p := NewPolicy()
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "elements": {"SPAN": {"Color": [{}]}},
+  "global": {"text-align": [{"enum": ["left", "right"]}]},
+  "deny": {"elements": {"td": {"text-align": [{}]}}}
+}`), p))
+
+	assert.Equal(t, "color: red; text-align: left",
+		p.Sanitize("span", "color: red; text-align: left; color: invalidValue"))
+	assert.Empty(t, p.Sanitize("td", "text-align: left"))
+
+	err := json.Unmarshal([]byte(`{"global": {"color": [{"regexp": "("}]}}`), p)
+	require.Error(t, err)
+	err = json.Unmarshal([]byte(`{"matching": {"(": {}}}`), p)
+	require.Error(t, err)
+}
+
+func TestPolicy_MarshalJSON_notSerializable(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("color").MatchingHandler(trueHandler).Globally()
+	_, err := json.Marshal(p)
+	require.ErrorIs(t, err, ErrNotSerializable)
+
+	p = NewPolicy()
+	p.AllowStyles("color").
+		MatchingTransform(func(property, value string) (string, bool) {
+			return value, true
+		}).Globally()
+	_, err = json.Marshal(p)
+	require.ErrorIs(t, err, ErrNotSerializable)
+}
+
+func TestPolicy_UnmarshalJSON_handlers(t *testing.T) {
+	p := NewPolicy()
+	err := json.Unmarshal([]byte(`{"global": {"color": [{"handler": "colr"}]}}`), p)
+	require.ErrorIs(t, err, ErrUnknownHandler)
+
+	err = json.Unmarshal([]byte(`{"global": {"color": [{"handler": "color"}]}}`), p)
+	require.NoError(t, err)
+	assert.Equal(t, "color: red", p.Sanitize("div", "color: red"))
+
+	err = json.Unmarshal(
+		[]byte(`{"global": {"color": [{"handler": "color", "default": true}]}}`), p)
+	require.Error(t, err)
+
+	p.AllowStyles("color").MatchingNamedHandler("colr").Globally()
+	require.ErrorIs(t, p.Err(), ErrUnknownHandler)
+}
+
+func TestPolicy_JSON_defaultHandler(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("color").Globally()
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"global": {"color": [{"default": true}]}}`, string(b))
+
+	// a registered handler with the name of the property doesn't replace its
+	// default handler
+	p2 := NewPolicy().RegisterHandlers(map[string]func(string) bool{
+		"color": trueHandler,
+	})
+	require.NoError(t, json.Unmarshal(b, p2))
+	assert.Empty(t, p2.Sanitize("div", "color: invalidValue"))
+	assert.Equal(t, "color: red", p2.Sanitize("div", "color: red"))
+}
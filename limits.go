@@ -0,0 +1,118 @@
+package css
+
+import (
+	"strings"
+
+	douceur "github.com/aymerick/douceur/css"
+)
+
+// Limits bounds the work Sanitize does for a single style attribute, so hostile
+// input can't burn CPU. A zero field means no limit.
+type Limits struct {
+	// MaxStyleLength is the maximum length of the style attribute in bytes. A
+	// longer style attribute is removed as a whole.
+	MaxStyleLength int `json:"max_style_length"`
+
+	// MaxDeclarations is the maximum number of declarations in the style
+	// attribute. A style attribute with more declarations is removed as a whole.
+	MaxDeclarations int `json:"max_declarations"`
+
+	// MaxValueLength is the maximum length of a value in bytes.
+	MaxValueLength int `json:"max_value_length"`
+
+	// MaxNestingDepth is the maximum nesting depth of functions in a value, like
+	// 2 for "calc(1px + min(2px, 3px))".
+	MaxNestingDepth int `json:"max_nesting_depth"`
+
+	// MaxShorthandTokens is the maximum number of space separated tokens in a
+	// value of any property, like 3 for "1px solid red". Handlers of shorthand
+	// properties try to match every group of tokens against every longhand, so
+	// their work grows with the square of this number.
+	MaxShorthandTokens int `json:"max_shorthand_tokens"`
+
+	// MaxStyleTokens is the maximum number of space separated tokens in all
+	// values of the style attribute, which bounds the work of handlers for the
+	// whole style attribute. A style attribute with more tokens is removed as a
+	// whole.
+	MaxStyleTokens int `json:"max_style_tokens"`
+}
+
+// DefaultLimits returns limits of a new policy.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxStyleLength:     32768,
+		MaxDeclarations:    128,
+		MaxValueLength:     8192,
+		MaxNestingDepth:    8,
+		MaxShorthandTokens: 32,
+		MaxStyleTokens:     256,
+	}
+}
+
+// Limits sets limits of the policy and returns the updated policy. A new policy
+// has DefaultLimits.
+func (self *Policy) Limits(limits Limits) *Policy {
+	self.limits = limits
+	return self
+}
+
+// exceeds returns true if n is over the limit.
+func exceeds(n, limit int) bool {
+	return limit > 0 && n > limit
+}
+
+// exceedsValue returns true if the value of the declaration is over any limit.
+func (self *Limits) exceedsValue(d *declaration) bool {
+	if exceeds(len(d.value), self.MaxValueLength) {
+		return true
+	} else if self.MaxNestingDepth > 0 &&
+		nestingDepth(d.normalized) > self.MaxNestingDepth {
+		return true
+	}
+	return self.MaxShorthandTokens > 0 &&
+		countTokens(d.normalized, self.MaxShorthandTokens+1) >
+			self.MaxShorthandTokens
+}
+
+// nestingDepth returns the maximum depth of nested parentheses in value.
+func nestingDepth(value string) int {
+	var depth, maxDepth int
+	for i := range len(value) {
+		switch value[i] {
+		case '(':
+			depth++
+			maxDepth = max(maxDepth, depth)
+		case ')':
+			depth = max(depth-1, 0)
+		}
+	}
+	return maxDepth
+}
+
+// exceedsTokens returns true if values of declarations have more tokens than
+// MaxStyleTokens.
+func (self *Limits) exceedsTokens(decs []*douceur.Declaration) bool {
+	if self.MaxStyleTokens <= 0 {
+		return false
+	}
+	var n int
+	for _, dec := range decs {
+		n += countTokens(dec.Value, self.MaxStyleTokens+1-n)
+		if n > self.MaxStyleTokens {
+			return true
+		}
+	}
+	return false
+}
+
+// countTokens returns the number of space separated tokens in value, but stops
+// counting at limit.
+func countTokens(value string, limit int) int {
+	var n int
+	for range strings.FieldsSeq(value) {
+		if n++; n >= limit {
+			break
+		}
+	}
+	return n
+}
@@ -0,0 +1,174 @@
+package css
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   Limits
+		in       string
+		expected string
+		rejected []Rejection
+	}{
+		{
+			name:     "MaxStyleLength",
+			limits:   Limits{MaxStyleLength: 20},
+			in:       "color: red; width: 10px",
+			rejected: []Rejection{{Value: "color: red; width: 10px", Reason: RejectLimit}},
+		},
+		{
+			name:     "MaxDeclarations",
+			limits:   Limits{MaxDeclarations: 1},
+			in:       "color: red; width: 10px",
+			rejected: []Rejection{{Value: "color: red; width: 10px;", Reason: RejectLimit}},
+		},
+		{
+			name:     "MaxValueLength",
+			limits:   Limits{MaxValueLength: 3},
+			in:       "color: red; width: 10px",
+			expected: "color: red",
+			rejected: []Rejection{{Property: "width", Value: "10px", Reason: RejectLimit}},
+		},
+		{
+			name:     "MaxNestingDepth",
+			limits:   Limits{MaxNestingDepth: 1},
+			in:       "transform: rotate(360); transform: translate(calc(1px))",
+			expected: "transform: rotate(360)",
+			rejected: []Rejection{
+				{Property: "transform", Value: "translate(calc(1px))", Reason: RejectLimit},
+			},
+		},
+		{
+			name:     "MaxShorthandTokens",
+			limits:   Limits{MaxShorthandTokens: 2},
+			in:       "border: 1px solid; border: 1px solid red",
+			expected: "border: 1px solid",
+			rejected: []Rejection{
+				{Property: "border", Value: "1px solid red", Reason: RejectLimit},
+			},
+		},
+		{
+			name:   "MaxStyleTokens",
+			limits: Limits{MaxStyleTokens: 4},
+			in:     "border: 1px solid; border: 1px solid red",
+			rejected: []Rejection{
+				{Value: "border: 1px solid; border: 1px solid red;", Reason: RejectLimit},
+			},
+		},
+		{
+			name:     "no limits",
+			in:       "color: red; width: 10px",
+			expected: "color: red; width: 10px",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPolicy().Limits(tt.limits)
+			p.AllowStyles("color", "width", "transform", "border").Globally()
+
+			clean, rejected := p.SanitizeWithReport("div", tt.in)
+			assert.Equal(t, tt.expected, clean)
+			assert.Equal(t, tt.rejected, rejected)
+
+			b, err := json.Marshal(p)
+			require.NoError(t, err)
+			p2 := NewPolicy()
+			require.NoError(t, json.Unmarshal(b, p2))
+			assert.Equal(t, tt.expected, p2.Sanitize("div", tt.in))
+		})
+	}
+}
+
+func TestDefaultLimits(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("color").Globally()
+	assert.Equal(t, DefaultLimits(), p.limits)
+
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "limits")
+
+	style := strings.Repeat("color: red; ", DefaultLimits().MaxDeclarations+1)
+	_, rejected := p.SanitizeWithReport("div", style)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, RejectLimit, rejected[0].Reason)
+}
+
+func TestLimits_partialJSON(t *testing.T) {
+	p := NewPolicy()
+	require.NoError(t, json.Unmarshal(
+		[]byte(`{"limits": {"max_style_length": 100}}`), p))
+	expected := DefaultLimits()
+	expected.MaxStyleLength = 100
+	assert.Equal(t, expected, p.limits)
+
+	require.NoError(t, json.Unmarshal([]byte(`{}`), p))
+	assert.Equal(t, DefaultLimits(), p.limits)
+
+	require.NoError(t, json.Unmarshal(
+		[]byte(`{"limits": {"max_declarations": 0}}`), p))
+	expected = DefaultLimits()
+	expected.MaxDeclarations = 0
+	assert.Equal(t, expected, p.limits)
+}
+
+func TestRecursiveCheck_linear(t *testing.T) {
+	// every token matches, but the last one doesn't, which was exponential
+	value := strings.Repeat("italic ", 60) + "@"
+	p := NewPolicy().Limits(Limits{})
+	p.AllowStyles("font").Globally()
+	assert.Empty(t, p.Sanitize("div", "font: "+value))
+}
+
+func TestDefaultLimits_work(t *testing.T) {
+	// the most tokens default limits allow, which were seconds of work, because
+	// every group of tokens is tried against every longhand
+	limits := DefaultLimits()
+	properties := []string{
+		"background", "border", "animation", "transition", "text-decoration",
+	}
+	for _, property := range properties {
+		for _, token := range []string{"1px", "1s", "a", "1vw"} {
+			value := strings.TrimSpace(
+				strings.Repeat(token+" ", limits.MaxShorthandTokens))
+			style := strings.Repeat(property+": "+value+"; ",
+				limits.MaxStyleTokens/limits.MaxShorthandTokens)
+			p := NewPolicy()
+			p.AllowStyles(property).Globally()
+			start := time.Now()
+			p.Sanitize("div", style)
+			assert.Less(t, time.Since(start), time.Second, property+": "+token)
+		}
+	}
+}
+
+func TestRemoveUnicode(t *testing.T) {
+	s, ok := removeUnicode(`\5c 72 ed`)
+	require.True(t, ok)
+	assert.Equal(t, `\72 ed`, s, "decoded characters are not decoded again")
+
+	s, ok = removeUnicode(`\72 \65 \64`)
+	require.True(t, ok)
+	assert.Equal(t, "red", s)
+
+	_, ok = removeUnicode(`\100072`)
+	assert.False(t, ok)
+}
+
+func TestLimits_Merge(t *testing.T) {
+	limits := Limits{MaxDeclarations: 1}
+	p := NewPolicy().Merge(NewPolicy().Limits(limits))
+	assert.Equal(t, limits, p.limits)
+
+	p = NewPolicy().Limits(limits).Merge(NewPolicy())
+	assert.Equal(t, limits, p.limits)
+}
@@ -0,0 +1,310 @@
+package css
+
+import (
+	"slices"
+	"strings"
+)
+
+// mathMaxDepth is the maximum nesting depth of math functions and parentheses,
+// accepted by math handlers.
+const mathMaxDepth = 8
+
+// mathType is the type of a math expression, like calc(100% - 20px).
+type mathType int
+
+const (
+	mathInvalid mathType = iota
+	mathNumber
+	mathPercent
+	mathLength
+	mathAngle
+	mathTime
+	mathFrequency
+	mathResolution
+)
+
+var mathUnits = map[string]mathType{
+	"%":   mathPercent,
+	"deg": mathAngle, "grad": mathAngle, "rad": mathAngle, "turn": mathAngle,
+	"s": mathTime, "ms": mathTime,
+	"hz": mathFrequency, "khz": mathFrequency,
+	"dpi": mathResolution, "dpcm": mathResolution, "dppx": mathResolution,
+	"x": mathResolution,
+}
+
+func init() {
+	for _, unit := range lengthUnits {
+		if unit != "%" {
+			mathUnits[unit] = mathLength
+		}
+	}
+}
+
+// mathFunctions are math functions, which MathHandler recognises, with their
+// number of arguments, 0 means any.
+var mathFunctions = map[string]int{
+	"calc":  1,
+	"min":   0,
+	"max":   0,
+	"clamp": 3,
+}
+
+// mathConstants are numeric constants of calc().
+var mathConstants = []string{"e", "pi", "infinity", "-infinity", "nan"}
+
+// isMath returns true if value is a single math function, like
+// "calc(100% - 20px)", "min(10px, 5vw)" or "clamp(1rem, 2vw, 2rem)", which
+// resolves to any of types. Operands of + and - must be of the same type, with
+// percentages resolved as lengths, one of * operands and the right operand of /
+// must be a number.
+func isMath(value string, types ...mathType) bool {
+	if !strings.HasSuffix(value, ")") {
+		return false
+	}
+	name, _, ok := strings.Cut(value, "(")
+	if _, fn := mathFunctions[name]; !ok || !fn {
+		return false
+	}
+
+	p := mathParser{tokens: tokenizeMath(value)}
+	if p.tokens == nil {
+		return false
+	}
+	t := p.function()
+	return t != mathInvalid && p.pos == len(p.tokens) && slices.Contains(types, t)
+}
+
+// mathToken is a token of a math expression.
+type mathToken struct {
+	// kind is one of: "number", "function", "(", ")", ",", "+", "-", "*", "/"
+	kind string
+
+	// text of the token, function name without "(", or the unit of a number
+	text string
+
+	// true if the token is preceded or followed by whitespace
+	spaceBefore, spaceAfter bool
+}
+
+// tokenizeMath returns tokens of value, or nil if it has unknown characters.
+func tokenizeMath(value string) []mathToken {
+	var tokens []mathToken
+	space := false
+	for i := 0; i < len(value); {
+		c := value[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			space = true
+			if len(tokens) > 0 {
+				tokens[len(tokens)-1].spaceAfter = true
+			}
+			i++
+			continue
+		case c == '(' || c == ')' || c == ',' || c == '*' || c == '/':
+			tokens = append(tokens, mathToken{kind: string(c), spaceBefore: space})
+			i++
+		case (c == '+' || c == '-') && !startsNumber(value[i+1:]):
+			if c == '-' && strings.HasPrefix(value[i:], "-infinity") {
+				tokens = append(tokens,
+					mathToken{kind: "number", text: "", spaceBefore: space})
+				i += len("-infinity")
+				break
+			}
+			tokens = append(tokens, mathToken{kind: string(c), spaceBefore: space})
+			i++
+		case c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9':
+			loc := numberUnit.FindStringSubmatchIndex(prefixToken(value[i:]))
+			if loc == nil {
+				return nil
+			}
+			unit := value[i+loc[4] : i+loc[5]]
+			tokens = append(tokens,
+				mathToken{kind: "number", text: unit, spaceBefore: space})
+			i += loc[1]
+		case c >= 'a' && c <= 'z':
+			j := i
+			for j < len(value) && (value[j] >= 'a' && value[j] <= 'z' ||
+				value[j] == '-') {
+				j++
+			}
+			name := value[i:j]
+			if j < len(value) && value[j] == '(' {
+				if _, ok := mathFunctions[name]; !ok {
+					return nil
+				}
+				tokens = append(tokens,
+					mathToken{kind: "function", text: name, spaceBefore: space})
+				j++
+			} else if slices.Contains(mathConstants, name) {
+				tokens = append(tokens,
+					mathToken{kind: "number", spaceBefore: space})
+			} else {
+				return nil
+			}
+			i = j
+		default:
+			return nil
+		}
+		space = false
+	}
+	return tokens
+}
+
+// startsNumber returns true if s starts with a digit or a decimal point.
+func startsNumber(s string) bool {
+	return s != "" && (s[0] == '.' || s[0] >= '0' && s[0] <= '9')
+}
+
+// prefixToken returns the number with unit at the start of s.
+func prefixToken(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '.' || c == '%' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z':
+		case (c == '+' || c == '-') && s[i-1] == 'e':
+		default:
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// mathParser is a recursive descent parser of math expressions, which returns
+// types of expressions.
+type mathParser struct {
+	tokens []mathToken
+	pos    int
+	depth  int
+}
+
+func (self *mathParser) peek() *mathToken {
+	if self.pos < len(self.tokens) {
+		return &self.tokens[self.pos]
+	}
+	return nil
+}
+
+func (self *mathParser) next(kind string) bool {
+	if t := self.peek(); t != nil && t.kind == kind {
+		self.pos++
+		return true
+	}
+	return false
+}
+
+// function parses a math function and returns its type.
+func (self *mathParser) function() mathType {
+	t := self.peek()
+	if t == nil || t.kind != "function" {
+		return mathInvalid
+	} else if self.depth++; self.depth > mathMaxDepth {
+		return mathInvalid
+	}
+	defer func() { self.depth-- }()
+	self.pos++
+
+	argc := mathFunctions[t.text]
+	result := mathInvalid
+	for n := 1; ; n++ {
+		arg := self.sum()
+		if n == 1 {
+			result = arg
+		} else {
+			result = addTypes(result, arg)
+		}
+		if result == mathInvalid {
+			return mathInvalid
+		}
+
+		if self.next(")") {
+			if argc != 0 && n != argc {
+				return mathInvalid
+			}
+			return result
+		} else if !self.next(",") || argc != 0 && n >= argc {
+			return mathInvalid
+		}
+	}
+}
+
+// sum parses operands separated by + or -, which must be surrounded by
+// whitespace.
+func (self *mathParser) sum() mathType {
+	result := self.product()
+	for result != mathInvalid {
+		t := self.peek()
+		if t == nil || t.kind != "+" && t.kind != "-" {
+			break
+		} else if !t.spaceBefore || !t.spaceAfter {
+			return mathInvalid
+		}
+		self.pos++
+		result = addTypes(result, self.product())
+	}
+	return result
+}
+
+// product parses operands separated by * or /.
+func (self *mathParser) product() mathType {
+	result := self.value()
+	for result != mathInvalid {
+		switch {
+		case self.next("*"):
+			right := self.value()
+			switch {
+			case result == mathNumber:
+				result = right
+			case right != mathNumber:
+				return mathInvalid
+			}
+		case self.next("/"):
+			if self.value() != mathNumber {
+				return mathInvalid
+			}
+		default:
+			return result
+		}
+	}
+	return result
+}
+
+// value parses a number, a nested function or an expression in parentheses.
+func (self *mathParser) value() mathType {
+	t := self.peek()
+	switch {
+	case t == nil:
+		return mathInvalid
+	case t.kind == "number":
+		self.pos++
+		if t.text == "" {
+			return mathNumber
+		} else if typ, ok := mathUnits[t.text]; ok {
+			return typ
+		}
+		return mathInvalid
+	case t.kind == "function":
+		return self.function()
+	case t.kind == "(":
+		if self.depth++; self.depth > mathMaxDepth {
+			return mathInvalid
+		}
+		defer func() { self.depth-- }()
+		self.pos++
+		if result := self.sum(); self.next(")") {
+			return result
+		}
+	}
+	return mathInvalid
+}
+
+// addTypes returns the type of a sum of a and b.
+func addTypes(a, b mathType) mathType {
+	switch {
+	case a == mathInvalid || b == mathInvalid:
+		return mathInvalid
+	case a == b:
+		return a
+	case a == mathPercent && b == mathLength, a == mathLength && b == mathPercent:
+		return mathLength
+	}
+	return mathInvalid
+}
@@ -0,0 +1,106 @@
+package css
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsMath(t *testing.T) {
+	tests := []struct {
+		value    string
+		types    []mathType
+		expected bool
+	}{
+		{"calc(100% - 20px)", []mathType{mathLength}, true},
+		{"calc(100% + -20px)", []mathType{mathLength}, true},
+		{"calc(100%-20px)", []mathType{mathLength}, false},
+		{"calc(100% -20px)", []mathType{mathLength}, false},
+		{"calc(1px+2px)", []mathType{mathLength}, false},
+		{"calc(2 * 10px)", []mathType{mathLength}, true},
+		{"calc(10px * 2)", []mathType{mathLength}, true},
+		{"calc(10px*2)", []mathType{mathLength}, true},
+		{"calc(10px * 2px)", []mathType{mathLength}, false},
+		{"calc(10px / 2)", []mathType{mathLength}, true},
+		{"calc(10px / 2px)", []mathType{mathLength}, false},
+		{"calc(10px + 1s)", []mathType{mathLength}, false},
+		{"calc(1e2px + .5em)", []mathType{mathLength}, true},
+		{"calc((1px + 2px) * 3)", []mathType{mathLength}, true},
+		{"calc(1px + (2px * 3)", []mathType{mathLength}, false},
+		{"calc(1px) 2px", []mathType{mathLength}, false},
+		{"calc(10px)", []mathType{mathTime}, false},
+		{"calc(1px, 2px)", []mathType{mathLength}, false},
+		{"calc()", []mathType{mathLength}, false},
+		{"calc(1foo)", []mathType{mathLength}, false},
+		{"calc(url(x))", []mathType{mathLength}, false},
+		{"calc(pi * 1deg)", []mathType{mathAngle}, true},
+		{"calc(1px * infinity)", []mathType{mathLength}, true},
+		{"calc(1px * -infinity)", []mathType{mathLength}, true},
+		{"min(10px, 5vw)", []mathType{mathLength}, true},
+		{"max(10px, 5vw, 50%)", []mathType{mathLength}, true},
+		{"max(10px, 1s)", []mathType{mathLength}, false},
+		{"clamp(1rem, 2vw, 2rem)", []mathType{mathLength}, true},
+		{"clamp(1rem, 2vw)", []mathType{mathLength}, false},
+		{"clamp(1rem, calc(2vw + 1px), min(2rem, 10%))", []mathType{mathLength}, true},
+		{"calc(1 + 2)", []mathType{mathNumber}, true},
+		{"calc(1s + 200ms)", []mathType{mathTime}, true},
+		{"calc(50% * 2)", []mathType{mathPercent}, true},
+		{"foo(1px)", []mathType{mathLength}, false},
+		{"10px", []mathType{mathLength}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isMath(tt.value, tt.types...))
+		})
+	}
+}
+
+func TestIsMath_depth(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("calc(", depth) + "1px" +
+			strings.Repeat(")", depth)
+	}
+	assert.True(t, isMath(nested(mathMaxDepth), mathLength))
+	assert.False(t, isMath(nested(mathMaxDepth+1), mathLength))
+	assert.False(t, isMath("calc("+strings.Repeat("(", mathMaxDepth)+"1px"+
+		strings.Repeat(")", mathMaxDepth+1), mathLength))
+}
+
+func TestMathHandlers(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("width", "margin", "font-size", "opacity", "z-index",
+		"transition-duration", "transform", "border").Globally()
+
+	tests := []struct {
+		in       string
+		expected bool
+	}{
+		{"width: calc(100% - 20px)", true},
+		{"width: calc(100% - 2s)", false},
+		{"margin: 0 calc(50% - 10px) 1px min(1px, 2vw)", true},
+		{"font-size: clamp(1rem, 2vw, 2rem)", true},
+		{"opacity: calc(1 / 2)", true},
+		{"opacity: 50%", true},
+		{"opacity: calc(50% + 10%)", true},
+		{"opacity: calc(1px / 2)", false},
+		{"z-index: calc(1 + 1)", true},
+		{"transition-duration: calc(1s + 10ms)", true},
+		{"transition-duration: calc(1s + 10px)", false},
+		{"transform: rotate(calc(90deg * 2))", true},
+		{"transform: rotate(0.5turn)", true},
+		{"transform: rotate(calc(1px))", false},
+		{"border: calc(1px + 1px) solid red", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if tt.expected {
+				assert.Equal(t, tt.in, p.Sanitize("div", tt.in))
+			} else {
+				assert.Empty(t, p.Sanitize("div", tt.in))
+			}
+		})
+	}
+}
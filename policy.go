@@ -1,14 +1,18 @@
 package css
 
 import (
+	"errors"
+	"maps"
+	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/aymerick/douceur/parser"
 )
 
-var cssUnicodeChar = regexp.MustCompile(`\\[0-9a-f]{1,6} ?`)
+var cssUnicodeChar = regexp.MustCompile(`\\[0-9a-fA-F]{1,6} ?`)
 
 // Policy encapsulates the allowlist of css styles that will be applied to the
 // sanitised style attributes.
@@ -19,12 +23,63 @@ type Policy struct {
 	elsAndStyles         map[string]map[string][]stylePolicy
 	elsMatchingAndStyles map[*regexp.Regexp]map[string][]stylePolicy
 	globalStyles         map[string][]stylePolicy
+
+	// deny rules, which take precedence over any style policy above
+	elsAndDenies         map[string]map[string][]stylePolicy
+	elsMatchingAndDenies map[*regexp.Regexp]map[string][]stylePolicy
+	globalDenies         map[string][]stylePolicy
+
+	// matching style policies and deny rules ordered by source of their regexp,
+	// sorted again every time they're added
+	matchers, denyMatchers []elementMatcher
+
+	// handlers registered by RegisterHandlers, resolvable by name
+	handlers map[string]func(string) bool
+
+	// recognised vendor prefixes, nil means defaultVendorPrefixes
+	prefixes     []string
+	prefixOutput PrefixMode
+
+	important  ImportantMode
+	duplicates DuplicateMode
+	limits     Limits
+
+	// sizes of units in px, nil means defaultUnitFactors
+	unitFactors map[string]float64
+
+	// allowed units of lengths, nil allows all units
+	units []string
+
+	// names of custom properties allowed in var(), nil disables var()
+	variables []string
+
+	// URL policy or validator of url() values, nil means defaultURLPolicy
+	urls         *URLPolicy
+	urlValidator func(*url.URL) bool
+
+	// errs contains misconfiguration reported by PolicyBuilder
+	errs []error
+}
+
+// elementRules contains style policies applicable to a single element, without
+// global ones.
+type elementRules struct {
+	allow map[string][]stylePolicy
+	deny  map[string][]stylePolicy
 }
 
 type stylePolicy struct {
 	// handler to validate
 	handler func(string) bool
 
+	// name of the handler, registered by RegisterHandlers or a property name of
+	// the default handler; it's empty for handlers, which have no name
+	handlerName string
+
+	// true if handler is the default handler of the property, rather than a
+	// handler chosen by name
+	defaultHandler bool
+
 	// optional pattern to match, when not nil the regexp needs to match otherwise
 	// the property is removed
 	regexp *regexp.Regexp
@@ -33,6 +88,30 @@ type stylePolicy struct {
 	// defined list of allowed values; property will be removed if the value is
 	// not allowed
 	enum []string
+
+	// optional range of numeric values
+	valueRange *Range
+
+	// when true all of handler, enum, regexp and range, which aren't empty, need
+	// to match, otherwise any of them
+	matchAll bool
+
+	// optional transformer of the value, applied after validation
+	transform func(property, value string) (string, bool)
+
+	// optional list of allowed vendor prefixes, nil allows all recognised
+	// prefixes
+	prefixes []string
+
+	// how !important is handled, ImportantDefault uses mode of the policy
+	important ImportantMode
+
+	// optional list of allowed units of lengths, nil uses units of the policy
+	units []string
+
+	// regexp of names of custom properties the style policy applies to, it's
+	// set for custom properties only
+	propertyRegexp *regexp.Regexp
 }
 
 // NewPolicy returns a blank policy with nothing allowed or permitted. This is
@@ -43,10 +122,228 @@ func NewPolicy() *Policy {
 		elsAndStyles:         make(map[string]map[string][]stylePolicy),
 		elsMatchingAndStyles: make(map[*regexp.Regexp]map[string][]stylePolicy),
 		globalStyles:         make(map[string][]stylePolicy),
+
+		elsAndDenies:         make(map[string]map[string][]stylePolicy),
+		elsMatchingAndDenies: make(map[*regexp.Regexp]map[string][]stylePolicy),
+		globalDenies:         make(map[string][]stylePolicy),
+
+		limits: DefaultLimits(),
+	}
+	return p
+}
+
+// Clone returns a deep copy of the policy, which can be extended without
+// changing the source policy.
+func (self *Policy) Clone() *Policy {
+	p := &Policy{
+		elsAndStyles:         cloneElements(self.elsAndStyles),
+		elsMatchingAndStyles: cloneElements(self.elsMatchingAndStyles),
+		globalStyles:         cloneStyles(self.globalStyles),
+
+		elsAndDenies:         cloneElements(self.elsAndDenies),
+		elsMatchingAndDenies: cloneElements(self.elsMatchingAndDenies),
+		globalDenies:         cloneStyles(self.globalDenies),
+
+		handlers: maps.Clone(self.handlers),
+		errs:     slices.Clone(self.errs),
+
+		prefixes:     slices.Clone(self.prefixes),
+		prefixOutput: self.prefixOutput,
+
+		important:  self.important,
+		duplicates: self.duplicates,
+		limits:     self.limits,
+
+		unitFactors: maps.Clone(self.unitFactors),
+		units:       slices.Clone(self.units),
+		variables:   slices.Clone(self.variables),
+
+		urlValidator: self.urlValidator,
+	}
+	if self.urls != nil {
+		p.urls = self.urls.clone()
 	}
+	p.sortMatchers()
 	return p
 }
 
+// Merge adds all style policies, deny rules and registered handlers of other
+// policy to this policy and returns the updated policy. The other policy isn't
+// changed.
+//
+// Merge is a union of style policies of both policies: a value allowed on an
+// element by any of them is allowed on this element by the merged policy, and
+// deny rules of both policies apply to it. Element specific style policies take
+// precedence over matching ones, so if only one of policies has specific style
+// policies for an element, matching style policies of another one, which apply
+// to this element, are added to its specific style policies. Element patterns
+// with the same source are merged together. Registered handlers of this policy
+// take precedence over handlers of other policy with the same name.
+//
+// Settings of both policies are merged too: vendor prefixes recognised by any of
+// them are recognised, var() references allowed by AllowVariables(...) of any
+// of them are allowed, sizes of units set by UnitFactors(...) of both policies
+// are used, units allowed by AllowUnits(...) of other policy keep applying to
+// its style policies, and a non-default setting, like PrefixOutput(...),
+// Important(...), Duplicates(...), Limits(...), AllowURLs(...) or
+// URLValidator(...), of this policy takes precedence over the setting of other
+// policy.
+func (self *Policy) Merge(other *Policy) *Policy {
+	if self.units != nil || other.units != nil {
+		other = other.Clone()
+		other.pinUnits()
+	}
+
+	selfMatching := keepMatching(self.elsAndStyles, other.elsAndStyles,
+		self.elsMatchingAndStyles)
+	otherMatching := keepMatching(other.elsAndStyles, self.elsAndStyles,
+		other.elsMatchingAndStyles)
+	mergeElements(self.elsAndStyles, selfMatching)
+	mergeElements(self.elsAndStyles, otherMatching)
+
+	mergeElements(self.elsAndStyles, other.elsAndStyles)
+	mergeElementsMatching(self.elsMatchingAndStyles, other.elsMatchingAndStyles)
+	mergeStyles(self.globalStyles, other.globalStyles)
+
+	mergeElements(self.elsAndDenies, other.elsAndDenies)
+	mergeElementsMatching(self.elsMatchingAndDenies, other.elsMatchingAndDenies)
+	mergeStyles(self.globalDenies, other.globalDenies)
+	self.sortMatchers()
+
+	for name, handler := range other.handlers {
+		if _, ok := self.handlers[name]; !ok {
+			if self.handlers == nil {
+				self.handlers = make(map[string]func(string) bool)
+			}
+			self.handlers[name] = handler
+		}
+	}
+
+	self.errs = append(self.errs, other.errs...)
+	self.mergeSettings(other)
+	return self
+}
+
+// mergeSettings merges settings of other policy into this policy.
+func (self *Policy) mergeSettings(other *Policy) {
+	if self.prefixes != nil || other.prefixes != nil {
+		self.prefixes = unionStrings(self.vendorPrefixes(), other.vendorPrefixes())
+	}
+	if self.prefixOutput == PrefixKeep {
+		self.prefixOutput = other.prefixOutput
+	}
+	if self.important == ImportantDefault {
+		self.important = other.important
+	}
+	if self.duplicates == DuplicateFallbacks {
+		self.duplicates = other.duplicates
+	}
+	if self.limits == DefaultLimits() {
+		self.limits = other.limits
+	}
+	if other.unitFactors != nil {
+		factors := maps.Clone(other.unitFactors)
+		maps.Copy(factors, self.unitFactors)
+		self.unitFactors = factors
+	}
+	if self.urls == nil && self.urlValidator == nil {
+		self.urlValidator = other.urlValidator
+		if other.urls != nil {
+			self.urls = other.urls.clone()
+		}
+	}
+	if other.variables != nil {
+		variables := unionStrings(self.variables, other.variables)
+		self.variables = append(make([]string, 0, len(variables)), variables...)
+	}
+}
+
+// unionStrings returns strings of a, followed by strings of b, which aren't in
+// a.
+func unionStrings(a, b []string) []string {
+	union := slices.Clone(a)
+	for _, s := range b {
+		if !slices.Contains(union, s) {
+			union = append(union, s)
+		}
+	}
+	return union
+}
+
+// keepMatching returns matching style policies, which apply to elements with
+// specific style policies in els of another policy only, so they still apply
+// to these elements after merging.
+func keepMatching(els, otherEls map[string]map[string][]stylePolicy,
+	elsMatching map[*regexp.Regexp]map[string][]stylePolicy,
+) map[string]map[string][]stylePolicy {
+	matchers := sortedMatchers(elsMatching)
+	kept := make(map[string]map[string][]stylePolicy)
+	for elementName, styles := range otherEls {
+		if len(styles) == 0 || len(els[elementName]) > 0 {
+			continue
+		}
+		if sps := mergeMatching(matchers, elementName); len(sps) > 0 {
+			kept[elementName] = sps
+		}
+	}
+	return kept
+}
+
+func mergeElements(to, from map[string]map[string][]stylePolicy) {
+	for elementName, styles := range from {
+		if _, ok := to[elementName]; !ok {
+			to[elementName] = make(map[string][]stylePolicy, len(styles))
+		}
+		mergeStyles(to[elementName], styles)
+	}
+}
+
+func mergeElementsMatching(to, from map[*regexp.Regexp]map[string][]stylePolicy) {
+	sources := make(map[string]*regexp.Regexp, len(to))
+	for regex := range to {
+		sources[regex.String()] = regex
+	}
+
+	for regex, styles := range from {
+		if existing, ok := sources[regex.String()]; ok {
+			regex = existing
+		} else {
+			to[regex] = make(map[string][]stylePolicy, len(styles))
+			sources[regex.String()] = regex
+		}
+		mergeStyles(to[regex], styles)
+	}
+}
+
+func mergeStyles(to, from map[string][]stylePolicy) {
+	for property, spl := range from {
+		for i := range spl {
+			to[property] = append(to[property], spl[i].clone())
+		}
+	}
+}
+
+func cloneElements[K comparable](els map[K]map[string][]stylePolicy,
+) map[K]map[string][]stylePolicy {
+	cloned := make(map[K]map[string][]stylePolicy, len(els))
+	for k, v := range els {
+		cloned[k] = cloneStyles(v)
+	}
+	return cloned
+}
+
+func cloneStyles(styles map[string][]stylePolicy) map[string][]stylePolicy {
+	cloned := make(map[string][]stylePolicy, len(styles))
+	for k, v := range styles {
+		spl := make([]stylePolicy, len(v))
+		for i, sp := range v {
+			spl[i] = sp.clone()
+		}
+		cloned[k] = spl
+	}
+	return cloned
+}
+
 // AllowStyles takes a range of CSS property names and returns a style policy
 // builder that allows you to specify the pattern and scope of the allowed
 // property.
@@ -57,6 +354,55 @@ func (self *Policy) AllowStyles(propertyNames ...string) *PolicyBuilder {
 	return NewPolicyBuilder(self, propertyNames...)
 }
 
+// RegisterHandlers adds named handlers to the policy and returns the updated
+// policy. Registered handlers can be used by PolicyBuilder.MatchingNamedHandler
+// and by name in JSON representation of the policy. A registered handler takes
+// precedence over the default handler of a property with the same name.
+func (self *Policy) RegisterHandlers(handlers map[string]func(string) bool,
+) *Policy {
+	if self.handlers == nil {
+		self.handlers = make(map[string]func(string) bool, len(handlers))
+	}
+	maps.Copy(self.handlers, handlers)
+	return self
+}
+
+// namedHandler returns a handler registered by RegisterHandlers or the default
+// handler of property name. It returns false and BaseHandler if there is no
+// such handler.
+func (self *Policy) namedHandler(name string) (func(string) bool, bool) {
+	if handler, ok := self.handlers[name]; ok {
+		return handler, true
+	} else if handler, ok := defaultStyleHandlers[name]; ok {
+		return handler, true
+	}
+	return BaseHandler, false
+}
+
+// DisallowStyles takes a range of CSS property names and returns a style policy
+// builder that allows you to specify the pattern and scope of the denied
+// property.
+//
+// Deny rules take precedence over any allow rule. Without Matching(...),
+// MatchingEnum(...) or MatchingHandler(...) any value of the property is denied,
+// otherwise only values matching them are denied, like
+//
+//	p.DisallowStyles("position").MatchingEnum("fixed").Globally()
+//
+// The deny rule is only added to the core policy when either Globally() or
+// OnElements(...) are called.
+func (self *Policy) DisallowStyles(propertyNames ...string) *PolicyBuilder {
+	b := NewPolicyBuilder(self, propertyNames...)
+	b.deny = true
+	return b
+}
+
+// Err returns misconfiguration of style policies, like validators ignored by
+// PolicyBuilder, or nil.
+func (self *Policy) Err() error {
+	return errors.Join(self.errs...)
+}
+
 // HasPolicies returns true if this Policy has any policy for given elementName.
 func (self *Policy) HasPolicies(elementName string) bool {
 	if len(self.globalStyles) > 0 {
@@ -76,24 +422,63 @@ func (self *Policy) HasPolicies(elementName string) bool {
 	return false
 }
 
+// Sanitize returns the style attribute of elementName with all declarations,
+// which aren't allowed by the policy, removed.
 func (self *Policy) Sanitize(elementName, style string) string {
 	if !self.HasPolicies(elementName) {
 		return ""
 	}
+	return self.sanitize(self.elementRules(elementName), style, nil)
+}
 
-	sps := self.elsAndStyles[elementName]
-	if len(sps) == 0 {
-		sps = map[string][]stylePolicy{}
-		// check for any matching elements, if we don't already have a policy found
-		// if multiple matches are found they will be overwritten, it's best to not
-		// have overlapping matchers
-		for regex, policies := range self.elsMatchingAndStyles {
-			if regex.MatchString(elementName) {
-				for k, v := range policies {
-					sps[k] = append(sps[k], v...)
-				}
-			}
+// SanitizeWithReport works like Sanitize, but also returns every declaration
+// removed from the style attribute, with the reason why it was removed.
+func (self *Policy) SanitizeWithReport(elementName, style string,
+) (string, []Rejection) {
+	var r report
+	clean := self.sanitize(self.elementRules(elementName), style, &r)
+	return clean, r
+}
+
+func (self *Policy) elementRules(elementName string) elementRules {
+	return resolveRules(elementName,
+		self.elsAndStyles[elementName], self.matchers,
+		self.elsAndDenies[elementName], self.denyMatchers)
+}
+
+// sortMatchers orders matching style policies and deny rules after they have
+// been added.
+func (self *Policy) sortMatchers() {
+	self.matchers = sortedMatchers(self.elsMatchingAndStyles)
+	self.denyMatchers = sortedMatchers(self.elsMatchingAndDenies)
+}
+
+// resolveRules returns style policies of elementName. Matching allow rules are
+// used only if there are no element specific allow rules, but matching deny
+// rules always apply.
+func resolveRules(elementName string, allow map[string][]stylePolicy,
+	allowMatchers []elementMatcher, deny map[string][]stylePolicy,
+	denyMatchers []elementMatcher,
+) elementRules {
+	if len(allow) == 0 {
+		allow = mergeMatching(allowMatchers, elementName)
+	}
+
+	denyMatching := mergeMatching(denyMatchers, elementName)
+	if len(denyMatching) > 0 {
+		for k, v := range deny {
+			denyMatching[k] = append(v[:len(v):len(v)], denyMatching[k]...)
 		}
+		deny = denyMatching
+	}
+	return elementRules{allow: allow, deny: deny}
+}
+
+func (self *Policy) sanitize(rules elementRules, style string, r *report,
+) string {
+	if exceeds(len(style), self.limits.MaxStyleLength) {
+		r.reject("", style, RejectLimit)
+		return ""
 	}
 
 	// Add semi-colon to end to fix parsing issue
@@ -104,71 +489,350 @@ func (self *Policy) Sanitize(elementName, style string) string {
 
 	decs, err := parser.ParseDeclarations(style)
 	if err != nil {
+		r.reject("", style, RejectParse)
+		return ""
+	} else if exceeds(len(decs), self.limits.MaxDeclarations) ||
+		self.limits.exceedsTokens(decs) {
+		r.reject("", style, RejectLimit)
 		return ""
 	}
 
-	var clean []string
-	prefixes := [...]string{
-		"-webkit-", "-moz-", "-ms-", "-o-", "mso-", "-xv-", "-atsc-", "-wap-",
-		"-khtml-", "prince-", "-ah-", "-hp-", "-ro-", "-rim-", "-tc-",
-	}
-
-	for _, dec := range decs {
-		tempProperty := strings.ToLower(dec.Property)
-		tempValue := removeUnicode(strings.ToLower(dec.Value))
-		for _, i := range prefixes {
-			tempProperty = strings.TrimPrefix(tempProperty, i)
-		}
-
-		if spl, ok := sps[tempProperty]; ok {
-			for _, sp := range spl {
-				switch {
-				case sp.handler != nil:
-					if sp.handler(tempValue) {
-						clean = append(clean, dec.Property+": "+dec.Value)
-						continue
-					}
-				case len(sp.enum) > 0:
-					if stringInSlice(tempValue, sp.enum) {
-						clean = append(clean, dec.Property+": "+dec.Value)
-						continue
-					}
-				case sp.regexp != nil:
-					if sp.regexp.MatchString(tempValue) {
-						clean = append(clean, dec.Property+": "+dec.Value)
-						continue
-					}
-				}
+	checked := make([]checkedDeclaration, len(decs))
+	check := func(c *checkedDeclaration, vars variables) {
+		key := styleKey(c.d.name)
+		c.emit, c.reason = self.check(&c.d, vars,
+			[][]stylePolicy{rules.deny[key], self.globalDenies[key]},
+			[][]stylePolicy{rules.allow[key], self.globalStyles[key]},
+			nil)
+	}
+
+	// Custom properties are checked first, because var() in other values are
+	// resolved with their values.
+	var declared map[string]string
+	var declaredImportant map[string]bool
+	for i, dec := range decs {
+		c := &checked[i]
+		c.d, c.ok = self.normalize(dec.Property, dec.Value)
+		c.d.important = c.d.important || dec.Important
+		if !c.ok || !strings.HasPrefix(c.d.name, customProperties) {
+			continue
+		}
+
+		check(c, nil)
+		if c.emit == "" || self.variables == nil {
+			continue
+		}
+		v, _ := self.normalize(c.d.property,
+			strings.TrimPrefix(c.emit, self.outputProperty(&c.d)+": "))
+		if declaredImportant[c.d.name] && !v.important {
+			continue
+		} else if declared == nil {
+			declared = make(map[string]string)
+			declaredImportant = make(map[string]bool)
+		}
+		declared[c.d.name] = v.normalized
+		declaredImportant[c.d.name] = v.important
+	}
+
+	vars := self.blockVariables(declared)
+	var clean []emitted
+	for i, dec := range decs {
+		c := &checked[i]
+		if !c.ok {
+			r.reject(dec.Property, dec.Value, RejectUnicode)
+			continue
+		} else if !strings.HasPrefix(c.d.name, customProperties) {
+			check(c, vars)
+		}
+
+		if c.emit == "" {
+			r.reject(dec.Property, dec.Value, c.reason)
+			continue
+		}
+		_, important := cutImportant(c.emit)
+		clean = append(clean, emitted{
+			property:  strings.ToLower(self.outputProperty(&c.d)),
+			output:    c.emit,
+			important: important,
+		})
+	}
+
+	if len(clean) > 0 {
+		return strings.Join(self.dedup(clean), "; ")
+	}
+	return ""
+}
+
+// checkedDeclaration is a declaration of a style attribute with the result of
+// its check.
+type checkedDeclaration struct {
+	d      declaration
+	ok     bool
+	emit   string
+	reason RejectReason
+}
+
+// declaration is a single declaration of a style attribute.
+type declaration struct {
+	// property and value as they're in the style attribute
+	property, value string
+
+	// lowercase property name without vendor prefix, which style policies are
+	// looked up by, and the removed prefix
+	name, prefix string
+
+	// value with unicode escape sequences decoded, and its lowercase version,
+	// which validators check
+	decoded, normalized string
+
+	// true if the declaration has !important
+	important bool
+
+	// true if the value is a single var() without fallback, which isn't
+	// validated
+	variable bool
+
+	// true if the value has var() of custom property, which isn't declared in
+	// the style attribute, so its value is unknown
+	unresolved bool
+}
+
+// normalize returns the declaration of property with value, or false if value
+// contains invalid unicode escape sequence.
+func (self *Policy) normalize(property, value string) (declaration, bool) {
+	d := declaration{property: property}
+	d.value, d.important = cutImportant(value)
+	decoded, ok := removeUnicode(d.value)
+	if !ok {
+		return d, false
+	}
+	d.decoded, d.normalized = decoded, strings.ToLower(decoded)
+
+	// Prefixes are matched in property itself, not in its lowercase version,
+	// which can be shorter, like with the Kelvin sign, so they can be cut from
+	// property by their length.
+	for _, prefix := range self.vendorPrefixes() {
+		name := property[len(d.prefix):]
+		if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			d.prefix += prefix
+		}
+	}
+	d.name = strings.ToLower(property[len(d.prefix):])
+	return d, true
+}
+
+// output returns the declaration with value, as it's emitted.
+func (self *Policy) output(d *declaration, value string, important bool,
+) string {
+	property := self.outputProperty(d)
+	if important {
+		return property + ": " + value + " !important"
+	}
+	return property + ": " + value
+}
+
+// outputProperty returns property name of the declaration, as it's emitted.
+func (self *Policy) outputProperty(d *declaration) string {
+	if self.prefixOutput == PrefixUnprefix {
+		return d.property[len(d.prefix):]
+	}
+	return d.property
+}
+
+// traceFunc is called by Policy.check for every style policy it tries. deny is
+// true for deny rules. group and index are indexes of the style policy in
+// check's arguments. ok is true if the style policy allowed the value, or the
+// deny rule matched it, and reason identifies the validator, which decided.
+type traceFunc func(deny bool, group, index int, ok bool, reason RejectReason)
+
+// check checks the declaration against deny rules and style policies, grouped
+// by scope. It returns the declaration to emit, as allowed by the first style
+// policy, which accepts it, or the reason why the declaration is removed.
+func (self *Policy) check(d *declaration, vars variables,
+	deny, allow [][]stylePolicy,
+	trace traceFunc,
+) (string, RejectReason) {
+	if self.limits.exceedsValue(d) {
+		return "", RejectLimit
+	} else if d.prefix != "" && self.prefixOutput == PrefixDrop {
+		return "", RejectPrefix
+	}
+
+	v := self.resolveVariables(d.normalized, vars.lookup)
+	if !v.ok {
+		return "", RejectVariable
+	} else if !self.allowURLs(d.decoded) {
+		return "", RejectURL
+	} else if v.value != d.normalized || v.bare || v.unresolved {
+		resolvedDec := *d
+		resolvedDec.normalized = v.value
+		resolvedDec.variable, resolvedDec.unresolved = v.bare, v.unresolved
+		d = &resolvedDec
+	}
+
+	for group, spl := range deny {
+		for i := range spl {
+			if !spl[i].appliesTo(d.name) {
+				continue
+			}
+			ok, reason := true, RejectReason(0)
+			if d.unresolved {
+				reason = RejectVariable
+			} else if !spl[i].empty() {
+				ok, reason = spl[i].match(d.normalized, self.factors())
+			}
+			if trace != nil {
+				trace(true, group, i, ok, reason)
+			}
+			if ok && d.unresolved {
+				return "", RejectVariable
+			} else if ok {
+				return "", RejectDenied
 			}
 		}
+	}
+
+	reason := RejectNoPolicy
+	for group, spl := range allow {
+		for i := range spl {
+			sp := &spl[i]
+			important := d.important
+			switch {
+			case !important:
+			case self.importantMode(sp) == ImportantReject:
+				if trace != nil {
+					trace(false, group, i, false, RejectImportant)
+				}
+				reason = RejectImportant
+				continue
+			case self.importantMode(sp) == ImportantStrip:
+				important = false
+			}
+
+			if !self.allowUnits(sp, d.normalized) {
+				if trace != nil {
+					trace(false, group, i, false, RejectUnit)
+				}
+				reason = RejectUnit
+				continue
+			}
 
-		if spl, ok := self.globalStyles[tempProperty]; ok {
-			for _, sp := range spl {
-				switch {
-				case sp.handler != nil:
-					if sp.handler(tempValue) {
-						clean = append(clean, dec.Property+": "+dec.Value)
-						continue
-					}
-				case len(sp.enum) > 0:
-					if stringInSlice(tempValue, sp.enum) {
-						clean = append(clean, dec.Property+": "+dec.Value)
-						continue
-					}
-				case sp.regexp != nil:
-					if sp.regexp.MatchString(tempValue) {
-						clean = append(clean, dec.Property+": "+dec.Value)
-						continue
-					}
+			value, ok, why := sp.accept(d, self.factors())
+			if ok && sp.transform != nil {
+				decoded, valid := removeUnicode(value)
+				if !valid || !self.allowURLs(decoded) {
+					ok, why = false, RejectURL
 				}
 			}
+			if trace != nil {
+				trace(false, group, i, ok, why)
+			}
+			if ok {
+				return self.output(d, value, important), why
+			}
+			reason = why
 		}
 	}
+	return "", reason
+}
 
-	if len(clean) > 0 {
-		return strings.Join(clean, "; ")
+// accept returns the value of the declaration to emit, if the style policy
+// allows it, or false and the reason why it isn't allowed. The value is
+// validated normalized, but original value is emitted or transformed. If it's
+// allowed, the reason identifies the validator, which allowed it. Factors are
+// sizes of units in px, used by the range.
+func (self *stylePolicy) accept(d *declaration, factors map[string]float64,
+) (string, bool, RejectReason) {
+	if !self.appliesTo(d.name) {
+		return "", false, RejectNoPolicy
+	} else if !self.allowPrefix(d.prefix) {
+		return "", false, RejectPrefix
 	}
-	return ""
+
+	var reason RejectReason
+	if d.unresolved && (len(self.enum) > 0 || self.valueRange != nil) {
+		return "", false, RejectVariable
+	} else if !self.empty() && !d.variable {
+		var ok bool
+		if ok, reason = self.match(d.normalized, factors); !ok {
+			return "", false, reason
+		}
+	}
+
+	if self.transform == nil {
+		return d.value, true, reason
+	} else if v, ok := self.transform(d.name, d.value); ok {
+		return v, true, reason
+	}
+	return "", false, RejectTransform
+}
+
+// appliesTo returns true if the style policy applies to property. It's false
+// for custom properties with names, which don't match regexp of the style
+// policy.
+func (self *stylePolicy) appliesTo(property string) bool {
+	return self.propertyRegexp == nil || self.propertyRegexp.MatchString(property)
+}
+
+// empty returns true if the style policy has nothing to validate with.
+func (self *stylePolicy) empty() bool {
+	return self.handler == nil && len(self.enum) == 0 && self.regexp == nil &&
+		self.valueRange == nil
+}
+
+// match returns true if value is allowed by the style policy, or false and the
+// reason why it isn't allowed. If it's allowed, the reason identifies the
+// validator, which allowed it. Factors are sizes of units in px, used by the
+// range.
+func (self *stylePolicy) match(value string, factors map[string]float64,
+) (bool, RejectReason) {
+	matched, reason := false, RejectHandler
+	for _, v := range [...]struct {
+		set    bool
+		reason RejectReason
+		match  func() bool
+	}{
+		{
+			self.handler != nil, RejectHandler,
+			func() bool { return self.handler(value) },
+		},
+		{
+			len(self.enum) > 0, RejectEnum,
+			func() bool { return stringInSlice(value, self.enum) },
+		},
+		{
+			self.regexp != nil, RejectRegexp,
+			func() bool { return self.regexp.MatchString(value) },
+		},
+		{
+			self.valueRange != nil, RejectRange,
+			func() bool { return self.valueRange.contains(value, factors) },
+		},
+	} {
+		if !v.set {
+			continue
+		}
+		reason = v.reason
+		if matched = v.match(); matched != self.matchAll {
+			// the first match of any, or the first mismatch of all
+			break
+		}
+	}
+
+	return matched, reason
+}
+
+func (self *stylePolicy) clone() stylePolicy {
+	sp := *self
+	sp.enum = slices.Clone(self.enum)
+	sp.prefixes = slices.Clone(self.prefixes)
+	sp.units = slices.Clone(self.units)
+	if self.valueRange != nil {
+		r := *self.valueRange
+		r.Units = slices.Clone(r.Units)
+		sp.valueRange = &r
+	}
+	return sp
 }
 
 // stringInSlice returns true if needle exists in haystack
@@ -181,13 +845,20 @@ func stringInSlice(needle string, haystack []string) bool {
 	return false
 }
 
-func removeUnicode(value string) string {
-	substitutedValue := value
-	currentLoc := cssUnicodeChar.FindStringIndex(substitutedValue)
-	for currentLoc != nil {
+// removeUnicode replaces all unicode escape sequences in value by the characters
+// they represent. It returns false if value contains an invalid sequence. Value
+// is scanned once, so decoded characters don't form new sequences.
+func removeUnicode(value string) (string, bool) {
+	locs := cssUnicodeChar.FindAllStringIndex(value, -1)
+	if locs == nil {
+		return value, true
+	}
 
-		character := substitutedValue[currentLoc[0]+1 : currentLoc[1]]
-		character = strings.TrimSpace(character)
+	var b strings.Builder
+	b.Grow(len(value))
+	var last int
+	for _, loc := range locs {
+		character := strings.TrimSpace(value[loc[0]+1 : loc[1]])
 		if len(character) < 4 {
 			character = strings.Repeat("0", 4-len(character)) + character
 		} else {
@@ -200,15 +871,14 @@ func removeUnicode(value string) string {
 				}
 			}
 		}
-		character = "\\u" + character
-		translatedChar, err := strconv.Unquote(`"` + character + `"`)
-		translatedChar = strings.TrimSpace(translatedChar)
+		translatedChar, err := strconv.Unquote(`"\u` + character + `"`)
 		if err != nil {
-			return ""
+			return "", false
 		}
-		substitutedValue = substitutedValue[0:currentLoc[0]] + translatedChar +
-			substitutedValue[currentLoc[1]:]
-		currentLoc = cssUnicodeChar.FindStringIndex(substitutedValue)
+		b.WriteString(value[last:loc[0]])
+		b.WriteString(strings.TrimSpace(translatedChar))
+		last = loc[1]
 	}
-	return substitutedValue
+	b.WriteString(value[last:])
+	return b.String(), true
 }
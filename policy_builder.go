@@ -1,17 +1,60 @@
 package css
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 )
 
+// ErrIgnoredValidator is returned by Policy.Err() when a PolicyBuilder has been
+// configured with validators, which it ignores.
+var ErrIgnoredValidator = errors.New("css: validator ignored")
+
+// ErrUnknownHandler is returned by Policy.Err() and Policy.UnmarshalJSON when a
+// handler name is neither registered by Policy.RegisterHandlers nor a property
+// name with a default handler.
+var ErrUnknownHandler = errors.New("css: unknown handler")
+
+type combineMode int
+
+const (
+	// combineFirst uses only the first of handler, enum, regexp or range
+	combineFirst combineMode = iota
+	// combineAny allows a value if any validator allows it
+	combineAny
+	// combineAll allows a value if all validators allow it
+	combineAll
+)
+
 type PolicyBuilder struct {
 	p *Policy
 
-	propertyNames []string
-	regexp        *regexp.Regexp
-	enum          []string
-	handler       func(string) bool
+	propertyNames  []string
+	regexp         *regexp.Regexp
+	enum           []string
+	valueRange     *Range
+	handler        func(string) bool
+	handlerName    string
+	defaultHandler bool
+	combine        combineMode
+	transform      func(property, value string) (string, bool)
+	prefixes       []string
+	important      ImportantMode
+	units          []string
+
+	// propertyRegexp is set by Policy.AllowCustomProperties
+	propertyRegexp *regexp.Regexp
+
+	// deny is true for builders created by Policy.DisallowStyles
+	deny bool
+
+	// errs contains misconfiguration of the builder, reported to the policy
+	// when the builder is bound to elements
+	errs []error
+
+	// reported is true after the builder has been checked by report
+	reported bool
 }
 
 func NewPolicyBuilder(p *Policy, propertyNames ...string) *PolicyBuilder {
@@ -30,6 +73,9 @@ func NewPolicyBuilder(p *Policy, propertyNames ...string) *PolicyBuilder {
 // Matching allows a regular expression to be applied to a nascent style policy,
 // and returns the style policy.
 func (self *PolicyBuilder) Matching(regex *regexp.Regexp) *PolicyBuilder {
+	if self.regexp != nil {
+		self.ignored("regexp %q replaced by %q", self.regexp, regex)
+	}
 	self.regexp = regex
 	return self
 }
@@ -37,6 +83,9 @@ func (self *PolicyBuilder) Matching(regex *regexp.Regexp) *PolicyBuilder {
 // MatchingEnum allows a list of allowed values to be applied to a nascent style
 // policy, and returns the style policy.
 func (self *PolicyBuilder) MatchingEnum(enum ...string) *PolicyBuilder {
+	if len(self.enum) > 0 {
+		self.ignored("enum %q replaced by %q", self.enum, enum)
+	}
 	self.enum = enum
 	return self
 }
@@ -45,23 +94,165 @@ func (self *PolicyBuilder) MatchingEnum(enum ...string) *PolicyBuilder {
 // returns the style policy.
 func (self *PolicyBuilder) MatchingHandler(handler func(string) bool,
 ) *PolicyBuilder {
-	self.handler = handler
+	if self.handler != nil {
+		self.ignored("handler replaced by another one")
+	}
+	self.handler, self.handlerName = handler, ""
 	return self
 }
 
+// MatchingNamedHandler allows a handler registered by Policy.RegisterHandlers
+// or the default handler of property name to be applied to a nascent style
+// policy, and returns the style policy. Unlike MatchingHandler, named handlers
+// are preserved in JSON representation of the policy.
+func (self *PolicyBuilder) MatchingNamedHandler(name string) *PolicyBuilder {
+	handler, ok := self.p.namedHandler(name)
+	if !ok {
+		self.errs = append(self.errs, fmt.Errorf("%w: %v: %q", ErrUnknownHandler,
+			self.propertyNames, name))
+	}
+	self.MatchingHandler(handler)
+	self.handlerName = name
+	return self
+}
+
+// MatchingTransform allows a transformer to be applied to a nascent style
+// policy, and returns the style policy. The transformer is called with
+// lowercase property name without vendor prefix and the value as it is in the
+// style attribute, after the value has been validated, and returns new value of
+// the property or false to remove it. The returned value is emitted as is, but
+// every url() in it must still be allowed by the URL policy.
+//
+// The transformer doesn't replace validators. Without other validators the
+// value is validated by the default handler of the property, like
+//
+//	p.AllowStyles("color").MatchingTransform(
+//	  func(property, value string) (string, bool) {
+//	    return strings.ToLower(value), true
+//	  }).Globally()
+func (self *PolicyBuilder) MatchingTransform(
+	transform func(property, value string) (string, bool),
+) *PolicyBuilder {
+	if self.transform != nil {
+		self.ignored("transformer replaced by another one")
+	}
+	self.transform = transform
+	return self
+}
+
+// MatchingDefaultHandler allows the default handler of every property to be
+// applied to a nascent style policy, together with other validators, and
+// returns the style policy. It's useful for adding restrictions on top of the
+// default handler, like
+//
+//	p.AllowStyles("color").MatchingDefaultHandler().
+//	  Matching(regexp.MustCompile(`^#`)).MatchingAll().Globally()
+func (self *PolicyBuilder) MatchingDefaultHandler() *PolicyBuilder {
+	self.defaultHandler = true
+	return self
+}
+
+// MatchingAny combines all validators of a nascent style policy, so a value is
+// allowed if any of them allows it, and returns the style policy.
+//
+// Without MatchingAny() or MatchingAll() only one validator is used, in order of
+// precedence: handler, enum, regexp, range.
+func (self *PolicyBuilder) MatchingAny() *PolicyBuilder {
+	self.setCombine(combineAny)
+	return self
+}
+
+// MatchingAll combines all validators of a nascent style policy, so a value is
+// allowed if all of them allow it, and returns the style policy.
+//
+// Without MatchingAny() or MatchingAll() only one validator is used, in order of
+// precedence: handler, enum, regexp, range.
+func (self *PolicyBuilder) MatchingAll() *PolicyBuilder {
+	self.setCombine(combineAll)
+	return self
+}
+
+func (self *PolicyBuilder) setCombine(mode combineMode) {
+	if self.combine != combineFirst && self.combine != mode {
+		self.ignored("MatchingAny() and MatchingAll() both used, the last wins")
+	}
+	self.combine = mode
+}
+
+func (self *PolicyBuilder) ignored(format string, a ...any) {
+	self.errs = append(self.errs, fmt.Errorf("%w: %v: %s", ErrIgnoredValidator,
+		self.propertyNames, fmt.Sprintf(format, a...)))
+}
+
+// report adds all misconfiguration of the builder to the policy. The builder is
+// checked once, so binding it again doesn't report the same misconfiguration
+// twice.
+func (self *PolicyBuilder) report() {
+	if !self.reported {
+		self.check()
+		self.reported = true
+	}
+	self.p.errs = append(self.p.errs, self.errs...)
+	self.errs = nil
+}
+
+// check records misconfiguration of combined validators of the builder.
+func (self *PolicyBuilder) check() {
+	if self.handler != nil && self.defaultHandler {
+		self.ignored("default handler replaced by MatchingHandler()")
+		self.defaultHandler = false
+	}
+
+	if self.combine == combineFirst {
+		var used string
+		for _, v := range [...]struct {
+			name string
+			set  bool
+		}{
+			{"handler", self.handler != nil || self.defaultHandler},
+			{"enum", len(self.enum) > 0},
+			{"regexp", self.regexp != nil},
+			{"range", self.valueRange != nil},
+		} {
+			switch {
+			case !v.set:
+			case used == "":
+				used = v.name
+			default:
+				self.ignored("%s ignored in favour of %s, "+
+					"use MatchingAny() or MatchingAll() to combine them", v.name, used)
+			}
+		}
+	}
+
+	if self.deny && self.transform != nil {
+		self.ignored("transformer of deny rule ignored")
+	}
+	if self.deny && self.prefixes != nil {
+		self.ignored("prefixes of deny rule ignored")
+	}
+	if self.deny && self.important != ImportantDefault {
+		self.ignored("important mode of deny rule ignored")
+	}
+	if self.deny && self.units != nil {
+		self.ignored("units of deny rule ignored")
+	}
+}
+
 // OnElements will bind a style policy to a given range of HTML elements and
 // return the updated policy.
 func (self *PolicyBuilder) OnElements(elements ...string) *Policy {
+	self.report()
 	for _, element := range elements {
 		element = strings.ToLower(element)
 
+		els := self.elements()
 		for _, attr := range self.propertyNames {
-			if _, ok := self.p.elsAndStyles[element]; !ok {
-				self.p.elsAndStyles[element] = make(map[string][]stylePolicy)
+			if _, ok := els[element]; !ok {
+				els[element] = make(map[string][]stylePolicy)
 			}
-			sp := self.stylePolicy(attr)
-			self.p.elsAndStyles[element][attr] = append(
-				self.p.elsAndStyles[element][attr], sp)
+			key := styleKey(attr)
+			els[element][key] = append(els[element][key], self.stylePolicy(attr))
 		}
 	}
 	return self.p
@@ -70,29 +261,59 @@ func (self *PolicyBuilder) OnElements(elements ...string) *Policy {
 // OnElementsMatching will bind a style policy to any HTML elements matching the
 // pattern and return the updated policy.
 func (self *PolicyBuilder) OnElementsMatching(regex *regexp.Regexp) *Policy {
-	if _, ok := self.p.elsMatchingAndStyles[regex]; !ok {
-		self.p.elsMatchingAndStyles[regex] = make(map[string][]stylePolicy)
+	self.report()
+	els := self.elementsMatching()
+	if _, ok := els[regex]; !ok {
+		els[regex] = make(map[string][]stylePolicy)
 	}
 
 	for _, attr := range self.propertyNames {
-		sp := self.stylePolicy(attr)
-		self.p.elsMatchingAndStyles[regex][attr] = append(
-			self.p.elsMatchingAndStyles[regex][attr], sp)
+		key := styleKey(attr)
+		els[regex][key] = append(els[regex][key], self.stylePolicy(attr))
 	}
+	self.p.sortMatchers()
 	return self.p
 }
 
 func (self *PolicyBuilder) stylePolicy(attr string) stylePolicy {
-	sp := stylePolicy{}
+	handler, handlerName := self.handler, self.handlerName
+	defaultHandler := handler == nil && (self.defaultHandler ||
+		len(self.enum) == 0 && self.regexp == nil && self.valueRange == nil &&
+			!self.deny)
+	if defaultHandler {
+		key := styleKey(attr)
+		handler, handlerName = GetDefaultHandler(key), key
+	}
+
+	sp := stylePolicy{
+		matchAll:       self.combine == combineAll,
+		propertyRegexp: self.propertyRegexp,
+	}
+	if attr != customProperties && styleKey(attr) == customProperties {
+		sp.propertyRegexp = regexp.MustCompile("^" + regexp.QuoteMeta(attr) + "$")
+	}
+	if !self.deny {
+		sp.transform, sp.prefixes = self.transform, self.prefixes
+		sp.important, sp.units = self.important, self.units
+	}
+
+	if self.combine != combineFirst {
+		sp.handler, sp.handlerName = handler, handlerName
+		sp.defaultHandler = defaultHandler
+		sp.enum, sp.regexp, sp.valueRange = self.enum, self.regexp, self.valueRange
+		return sp
+	}
+
 	switch {
-	case self.handler != nil:
-		sp.handler = self.handler
+	case handler != nil:
+		sp.handler, sp.handlerName = handler, handlerName
+		sp.defaultHandler = defaultHandler
 	case len(self.enum) > 0:
 		sp.enum = self.enum
 	case self.regexp != nil:
 		sp.regexp = self.regexp
-	default:
-		sp.handler = GetDefaultHandler(attr)
+	case self.valueRange != nil:
+		sp.valueRange = self.valueRange
 	}
 	return sp
 }
@@ -100,14 +321,32 @@ func (self *PolicyBuilder) stylePolicy(attr string) stylePolicy {
 // Globally will bind a style policy to all HTML elements and return the updated
 // policy.
 func (self *PolicyBuilder) Globally() *Policy {
+	self.report()
+	global := self.global()
 	for _, attr := range self.propertyNames {
-		if _, ok := self.p.globalStyles[attr]; !ok {
-			self.p.globalStyles[attr] = []stylePolicy{}
-		}
-
-		// Use only one strategy for validating styles, fallback to default.
-		sp := self.stylePolicy(attr)
-		self.p.globalStyles[attr] = append(self.p.globalStyles[attr], sp)
+		key := styleKey(attr)
+		global[key] = append(global[key], self.stylePolicy(attr))
 	}
 	return self.p
 }
+
+func (self *PolicyBuilder) elements() map[string]map[string][]stylePolicy {
+	if self.deny {
+		return self.p.elsAndDenies
+	}
+	return self.p.elsAndStyles
+}
+
+func (self *PolicyBuilder) elementsMatching() map[*regexp.Regexp]map[string][]stylePolicy {
+	if self.deny {
+		return self.p.elsMatchingAndDenies
+	}
+	return self.p.elsMatchingAndStyles
+}
+
+func (self *PolicyBuilder) global() map[string][]stylePolicy {
+	if self.deny {
+		return self.p.globalDenies
+	}
+	return self.p.globalStyles
+}
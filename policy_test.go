@@ -2,9 +2,13 @@ package css
 
 import (
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func trueHandler(s string) bool { return true }
@@ -84,14 +88,46 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"aLiGn-cOntEnt: cEntEr;"},
 			expected: []string{"aLiGn-cOntEnt: cEntEr"},
 		},
+		{
+			in: []string{
+				"align-content: flex-start", "align-content: start",
+				"align-content: space-evenly", "align-content: first baseline",
+				"align-content: safe center", "align-content: unsafe end",
+				"align-content: safe space-between", "align-content: left",
+				"align-content: middle baseline",
+			},
+			expected: []string{
+				"align-content: flex-start", "align-content: start",
+				"align-content: space-evenly", "align-content: first baseline",
+				"align-content: safe center", "align-content: unsafe end", "",
+				"", "",
+			},
+		},
 		{
 			in:       []string{"align-items: center;"},
 			expected: []string{"align-items: center"},
 		},
+		{
+			in: []string{
+				"align-items: last baseline", "align-items: self-start",
+				"align-items: auto",
+			},
+			expected: []string{
+				"align-items: last baseline", "align-items: self-start", "",
+			},
+		},
 		{
 			in:       []string{"align-self: center;"},
 			expected: []string{"align-self: center"},
 		},
+		{
+			in: []string{
+				"align-self: auto", "align-self: unsafe flex-end",
+			},
+			expected: []string{
+				"align-self: auto", "align-self: unsafe flex-end",
+			},
+		},
 		{
 			in:       []string{"all: initial;"},
 			expected: []string{"all: initial"},
@@ -154,6 +190,21 @@ func TestDefaultStyleHandlers(t *testing.T) {
 				"animation-timing-function: steps(2, start)",
 			},
 		},
+		{
+			in: []string{
+				"aspect-ratio: auto", "aspect-ratio: 16/9",
+				"aspect-ratio: 16 / 9", "aspect-ratio: 1.5",
+				"aspect-ratio: auto 4/3", "aspect-ratio: 4/3 auto",
+				"aspect-ratio: 16 9", "aspect-ratio: -1", "aspect-ratio: 1/2/3",
+				"aspect-ratio: 16px/9", "aspect-ratio: auto auto",
+			},
+			expected: []string{
+				"aspect-ratio: auto", "aspect-ratio: 16/9",
+				"aspect-ratio: 16 / 9", "aspect-ratio: 1.5",
+				"aspect-ratio: auto 4/3", "aspect-ratio: 4/3 auto", "", "", "",
+				"", "",
+			},
+		},
 		{
 			in:       []string{"backface-visibility: hidden"},
 			expected: []string{"backface-visibility: hidden"},
@@ -226,10 +277,34 @@ func TestDefaultStyleHandlers(t *testing.T) {
 				"background-size: initial",
 			},
 		},
+		{
+			in:       []string{"block-size: auto"},
+			expected: []string{"block-size: auto"},
+		},
 		{
 			in:       []string{"border: 4px dotted blue;", "border: initial;"},
 			expected: []string{"border: 4px dotted blue", "border: initial"},
 		},
+		{
+			in:       []string{"border-block-end-color: red"},
+			expected: []string{"border-block-end-color: red"},
+		},
+		{
+			in:       []string{"border-block-end-width: thick"},
+			expected: []string{"border-block-end-width: thick"},
+		},
+		{
+			in: []string{
+				"border-block-start: thin dashed rgb(0 0 0 / 50%)",
+			},
+			expected: []string{
+				"border-block-start: thin dashed rgb(0 0 0 / 50%)",
+			},
+		},
+		{
+			in:       []string{"border-block-style: solid dotted"},
+			expected: []string{"border-block-style: solid dotted"},
+		},
 		{
 			in: []string{
 				"border-bottom: 4px dotted blue;",
@@ -274,6 +349,10 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"border-color: coral;"},
 			expected: []string{"border-color: coral"},
 		},
+		{
+			in:       []string{"border-end-start-radius: 4px 10%"},
+			expected: []string{"border-end-start-radius: 4px 10%"},
+		},
 		{
 			in: []string{
 				"border-image: url(https://border.png) 30 round;",
@@ -312,6 +391,27 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"border-image-width: 10px;"},
 			expected: []string{"border-image-width: 10px"},
 		},
+		{
+			in:       []string{"border-inline: 1px solid red"},
+			expected: []string{"border-inline: 1px solid red"},
+		},
+		{
+			in: []string{
+				"border-inline-color: red blue",
+				"border-inline-color: red blue green",
+			},
+			expected: []string{
+				"border-inline-color: red blue", "",
+			},
+		},
+		{
+			in:       []string{"border-inline-start-style: solid"},
+			expected: []string{"border-inline-start-style: solid"},
+		},
+		{
+			in:       []string{"border-inline-width: thin 2px"},
+			expected: []string{"border-inline-width: thin 2px"},
+		},
 		{
 			in:       []string{"border-left: 4px dotted blue;"},
 			expected: []string{"border-left: 4px dotted blue"},
@@ -360,6 +460,14 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"border-spacing: 15px;"},
 			expected: []string{"border-spacing: 15px"},
 		},
+		{
+			in:       []string{"border-start-end-radius: red"},
+			expected: []string{""},
+		},
+		{
+			in:       []string{"border-start-start-radius: 4px"},
+			expected: []string{"border-start-start-radius: 4px"},
+		},
 		{
 			in: []string{
 				"border-style: dotted;",
@@ -472,6 +580,10 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"clear: both;"},
 			expected: []string{"clear: both"},
 		},
+		{
+			in:       []string{"clear: inline-end"},
+			expected: []string{"clear: inline-end"},
+		},
 		{
 			in: []string{
 				"clip: rect(0px,60px,200px,0px);",
@@ -598,6 +710,10 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"float: right"},
 			expected: []string{"float: right"},
 		},
+		{
+			in:       []string{"float: inline-start"},
+			expected: []string{"float: inline-start"},
+		},
 		{
 			in: []string{
 				"font: italic bold 12px/30px Georgia, serif",
@@ -608,6 +724,10 @@ func TestDefaultStyleHandlers(t *testing.T) {
 				"font: icon",
 			},
 		},
+		{
+			in:       []string{"font: small-caps bold 12px serif"},
+			expected: []string{"font: small-caps bold 12px serif"},
+		},
 		{
 			in: []string{
 				"font-family: 'Times New Roman', Times, serif",
@@ -618,6 +738,30 @@ func TestDefaultStyleHandlers(t *testing.T) {
 				"font-family: comic sans ms, cursive, sans-serif",
 			},
 		},
+		{
+			in: []string{
+				"font-feature-settings: normal",
+				`font-feature-settings: "liga"`,
+				`font-feature-settings: "liga" 0`,
+				`font-feature-settings: "SS01" on, 'smcp', "swsh" 2`,
+				`font-feature-settings: "liga" off`,
+				`font-feature-settings: "liga" -1`,
+				`font-feature-settings: "liga" 1.5`,
+				`font-feature-settings: "lig"`,
+				`font-feature-settings: "ligat"`, "font-feature-settings: liga",
+				`font-feature-settings: "li\a"`,
+				`font-feature-settings: "liga" 1 2`,
+				`font-feature-settings: "liga",`,
+			},
+			expected: []string{
+				"font-feature-settings: normal",
+				`font-feature-settings: "liga"`,
+				`font-feature-settings: "liga" 0`,
+				`font-feature-settings: "SS01" on, 'smcp', "swsh" 2`,
+				`font-feature-settings: "liga" off`, "", "", "", "", "", "", "",
+				"",
+			},
+		},
 		{
 			in:       []string{"font-kerning: normal"},
 			expected: []string{"font-kerning: normal"},
@@ -626,6 +770,15 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"font-language-override: normal"},
 			expected: []string{"font-language-override: normal"},
 		},
+		{
+			in: []string{
+				"font-optical-sizing: auto", "font-optical-sizing: none",
+				"font-optical-sizing: 12",
+			},
+			expected: []string{
+				"font-optical-sizing: auto", "font-optical-sizing: none", "",
+			},
+		},
 		{
 			in:       []string{"font-size: large"},
 			expected: []string{"font-size: large"},
@@ -650,18 +803,99 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"font-variant: small-caps"},
 			expected: []string{"font-variant: small-caps"},
 		},
+		{
+			in: []string{
+				"font-variant: small-caps", "font-variant: none",
+				"font-variant: all-small-caps oldstyle-nums no-common-ligatures",
+				"font-variant: swash(flowing) jis83 full-width super",
+				"font-variant: small-caps petite-caps",
+				"font-variant: sub super", "font-variant: small-caps foo",
+			},
+			expected: []string{
+				"font-variant: small-caps", "font-variant: none",
+				"font-variant: all-small-caps oldstyle-nums no-common-ligatures",
+				"font-variant: swash(flowing) jis83 full-width super", "", "",
+				"",
+			},
+		},
+		{
+			in: []string{
+				"font-variant-alternates: styleset(fancy, alt) historical-forms",
+				"font-variant-alternates: stylistic(fancy, alt)",
+				"font-variant-alternates: swash(1)",
+			},
+			expected: []string{
+				"font-variant-alternates: styleset(fancy, alt) historical-forms",
+				"", "",
+			},
+		},
 		{
 			in:       []string{"font-variant-caps: small-caps"},
 			expected: []string{"font-variant-caps: small-caps"},
 		},
+		{
+			in: []string{
+				"font-variant-east-asian: jis04 proportional-width ruby",
+				"font-variant-east-asian: jis78 simplified",
+			},
+			expected: []string{
+				"font-variant-east-asian: jis04 proportional-width ruby", "",
+			},
+		},
+		{
+			in: []string{
+				"font-variant-ligatures: none",
+				"font-variant-ligatures: no-common-ligatures contextual",
+				"font-variant-ligatures: common-ligatures no-common-ligatures",
+			},
+			expected: []string{
+				"font-variant-ligatures: none",
+				"font-variant-ligatures: no-common-ligatures contextual", "",
+			},
+		},
+		{
+			in: []string{
+				"font-variant-numeric: oldstyle-nums tabular-nums diagonal-fractions",
+				"font-variant-numeric: ordinal slashed-zero",
+				"font-variant-numeric: lining-nums oldstyle-nums",
+				"font-variant-numeric: normal ordinal",
+			},
+			expected: []string{
+				"font-variant-numeric: oldstyle-nums tabular-nums diagonal-fractions",
+				"font-variant-numeric: ordinal slashed-zero", "", "",
+			},
+		},
 		{
 			in:       []string{"font-variant-position: sub"},
 			expected: []string{"font-variant-position: sub"},
 		},
+		{
+			in: []string{
+				`font-variation-settings: "wght" 400`,
+				`font-variation-settings: "wght" 400, "slnt" -7.5`,
+				`font-variation-settings: "wdth" calc(100 / 2)`,
+				`font-variation-settings: "wght"`,
+				`font-variation-settings: "wght" on`,
+			},
+			expected: []string{
+				`font-variation-settings: "wght" 400`,
+				`font-variation-settings: "wght" 400, "slnt" -7.5`,
+				`font-variation-settings: "wdth" calc(100 / 2)`, "", "",
+			},
+		},
 		{
 			in:       []string{"font-weight: normal"},
 			expected: []string{"font-weight: normal"},
 		},
+		{
+			in: []string{
+				"gap: 10px", "gap: 1em 5%", "gap: normal 10px",
+				"gap: 1px 2px 3px",
+			},
+			expected: []string{
+				"gap: 10px", "gap: 1em 5%", "gap: normal 10px", "",
+			},
+		},
 		{
 			in:       []string{"grid: 150px / auto auto auto;", "grid: none;"},
 			expected: []string{"grid: 150px / auto auto auto", "grid: none"},
@@ -768,10 +1002,44 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"height: 50px;", "height: auto;"},
 			expected: []string{"height: 50px", "height: auto"},
 		},
+		{
+			in: []string{
+				"hyphenate-character: auto", `hyphenate-character: "-"`,
+				`hyphenate-character: "-" "-"`, "hyphenate-character: -",
+			},
+			expected: []string{
+				"hyphenate-character: auto", `hyphenate-character: "-"`, "", "",
+			},
+		},
 		{
 			in:       []string{"hyphens: manual;"},
 			expected: []string{"hyphens: manual"},
 		},
+		{
+			in:       []string{"inline-size: 100px"},
+			expected: []string{"inline-size: 100px"},
+		},
+		{
+			in: []string{
+				"inset: 0", "inset: 1px auto 2px 3%",
+				"inset: 1px 2px 3px 4px 5px",
+			},
+			expected: []string{
+				"inset: 0", "inset: 1px auto 2px 3%", "",
+			},
+		},
+		{
+			in:       []string{"inset-block-end: foo"},
+			expected: []string{""},
+		},
+		{
+			in:       []string{"inset-inline: auto 0"},
+			expected: []string{"inset-inline: auto 0"},
+		},
+		{
+			in:       []string{"inset-inline-start: 10px"},
+			expected: []string{"inset-inline-start: 10px"},
+		},
 		{
 			in:       []string{"isolation: isolate;"},
 			expected: []string{"isolation: isolate"},
@@ -784,6 +1052,31 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"justify-content: center;"},
 			expected: []string{"justify-content: center"},
 		},
+		{
+			in: []string{
+				"justify-content: space-evenly", "justify-content: safe right",
+				"justify-content: baseline", "justify-content: inherit",
+			},
+			expected: []string{
+				"justify-content: space-evenly", "justify-content: safe right",
+				"", "justify-content: inherit",
+			},
+		},
+		{
+			in: []string{
+				"justify-items: legacy", "justify-items: legacy center",
+				"justify-items: right legacy", "justify-items: legacy start",
+				"justify-items: safe left",
+			},
+			expected: []string{
+				"justify-items: legacy", "justify-items: legacy center",
+				"justify-items: right legacy", "", "justify-items: safe left",
+			},
+		},
+		{
+			in:       []string{"justify-self: auto", "justify-self: end"},
+			expected: []string{"justify-self: auto", "justify-self: end"},
+		},
 		{
 			in:       []string{"left: 150px;"},
 			expected: []string{"left: 150px"},
@@ -826,10 +1119,25 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"margin: 150px;", "margin: auto;"},
 			expected: []string{"margin: 150px", "margin: auto"},
 		},
+		{
+			in:       []string{"margin-block-start: -1em"},
+			expected: []string{"margin-block-start: -1em"},
+		},
 		{
 			in:       []string{"margin-bottom: 150px;", "margin-bottom: auto;"},
 			expected: []string{"margin-bottom: 150px", "margin-bottom: auto"},
 		},
+		{
+			in: []string{
+				"margin-inline: 1px", "margin-inline: 1px auto",
+				"margin-inline: 1px 2px 3px", "margin-inline: inherit",
+				"margin-inline: 1px inherit",
+			},
+			expected: []string{
+				"margin-inline: 1px", "margin-inline: 1px auto", "",
+				"margin-inline: inherit", "",
+			},
+		},
 		{
 			in:       []string{"margin-left: 150px;"},
 			expected: []string{"margin-left: 150px"},
@@ -846,10 +1154,18 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"max-height: 150px;", "max-height: initial;"},
 			expected: []string{"max-height: 150px", "max-height: initial"},
 		},
+		{
+			in:       []string{"max-inline-size: none"},
+			expected: []string{"max-inline-size: none"},
+		},
 		{
 			in:       []string{"max-width: 150px;"},
 			expected: []string{"max-width: 150px"},
 		},
+		{
+			in:       []string{"min-block-size: 10em"},
+			expected: []string{"min-block-size: 10em"},
+		},
 		{
 			in:       []string{"min-height: 150px;", "min-height: initial;"},
 			expected: []string{"min-height: 150px", "min-height: initial"},
@@ -862,6 +1178,18 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"mix-blend-mode: darken;"},
 			expected: []string{"mix-blend-mode: darken"},
 		},
+		{
+			in: []string{
+				"mso-hide: all", "mso-line-height-rule: exactly",
+				"mso-line-height-rule: at-least", "mso-line-height-rule: 10px",
+				"mso-table-lspace: 0pt", "mso-padding-alt: 0 10px",
+			},
+			expected: []string{
+				"mso-hide: all", "mso-line-height-rule: exactly",
+				"mso-line-height-rule: at-least", "",
+				"mso-table-lspace: 0pt", "mso-padding-alt: 0 10px",
+			},
+		},
 		{
 			in:       []string{"object-fit: cover;"},
 			expected: []string{"object-fit: cover"},
@@ -910,6 +1238,10 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"overflow: scroll;"},
 			expected: []string{"overflow: scroll"},
 		},
+		{
+			in:       []string{"overflow-inline: hidden"},
+			expected: []string{"overflow-inline: hidden"},
+		},
 		{
 			in:       []string{"overflow-x: scroll;"},
 			expected: []string{"overflow-x: scroll"},
@@ -930,10 +1262,22 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"padding: 55px;"},
 			expected: []string{"padding: 55px"},
 		},
+		{
+			in:       []string{"padding-block-end: 0"},
+			expected: []string{"padding-block-end: 0"},
+		},
 		{
 			in:       []string{"padding-bottom: 55px;", "padding-bottom: initial;"},
 			expected: []string{"padding-bottom: 55px", "padding-bottom: initial"},
 		},
+		{
+			in: []string{
+				"padding-inline: 1px 2%", "padding-inline: auto",
+			},
+			expected: []string{
+				"padding-inline: 1px 2%", "",
+			},
+		},
 		{
 			in:       []string{"padding-left: 55px;"},
 			expected: []string{"padding-left: 55px"},
@@ -966,6 +1310,36 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"perspective-origin: left;"},
 			expected: []string{"perspective-origin: left"},
 		},
+		{
+			in: []string{
+				"place-content: center",
+				"place-content: space-between safe end",
+				"place-content: first baseline space-evenly",
+				"place-content: center baseline",
+				"place-content: center inherit",
+			},
+			expected: []string{
+				"place-content: center",
+				"place-content: space-between safe end",
+				"place-content: first baseline space-evenly", "", "",
+			},
+		},
+		{
+			in: []string{
+				"place-items: center legacy", "place-items: auto center",
+			},
+			expected: []string{
+				"place-items: center legacy", "",
+			},
+		},
+		{
+			in: []string{
+				"place-self: auto stretch", "place-self: start end center",
+			},
+			expected: []string{
+				"place-self: auto stretch", "",
+			},
+		},
 		{
 			in:       []string{"pointer-events: auto;"},
 			expected: []string{"pointer-events: auto"},
@@ -982,10 +1356,18 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"resize: both;"},
 			expected: []string{"resize: both"},
 		},
+		{
+			in:       []string{"resize: inline"},
+			expected: []string{"resize: inline"},
+		},
 		{
 			in:       []string{"right: 10px;"},
 			expected: []string{"right: 10px"},
 		},
+		{
+			in:       []string{"row-gap: calc(1em + 2px)"},
+			expected: []string{"row-gap: calc(1em + 2px)"},
+		},
 		{
 			in:       []string{"scroll-behavior: smooth;"},
 			expected: []string{"scroll-behavior: smooth"},
@@ -1002,6 +1384,10 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"text-align: justify;"},
 			expected: []string{"text-align: justify"},
 		},
+		{
+			in:       []string{"text-align: start"},
+			expected: []string{"text-align: start"},
+		},
 		{
 			in:       []string{"text-align-last: justify;"},
 			expected: []string{"text-align-last: justify"},
@@ -1026,6 +1412,25 @@ func TestDefaultStyleHandlers(t *testing.T) {
 				"text-decoration: initial",
 			},
 		},
+		{
+			in: []string{
+				"text-decoration: underline",
+				"text-decoration: underline dotted red",
+				"text-decoration: underline overline 2px wavy rgb(255 0 0)",
+				"text-decoration: red from-font underline",
+				"text-decoration: underline 10% solid",
+				"text-decoration: underline red overline",
+				"text-decoration: 1px 2px underline",
+				"text-decoration: solid dotted",
+			},
+			expected: []string{
+				"text-decoration: underline",
+				"text-decoration: underline dotted red",
+				"text-decoration: underline overline 2px wavy rgb(255 0 0)",
+				"text-decoration: red from-font underline",
+				"text-decoration: underline 10% solid", "", "", "",
+			},
+		},
 		{
 			in:       []string{"text-decoration-color: red;"},
 			expected: []string{"text-decoration-color: red"},
@@ -1034,10 +1439,63 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"text-decoration-line: underline underline;"},
 			expected: []string{"text-decoration-line: underline underline"},
 		},
+		{
+			in: []string{
+				"text-decoration-skip-ink: all",
+				"text-decoration-skip-ink: edges",
+			},
+			expected: []string{
+				"text-decoration-skip-ink: all", "",
+			},
+		},
 		{
 			in:       []string{"text-decoration-style: solid;"},
 			expected: []string{"text-decoration-style: solid"},
 		},
+		{
+			in: []string{
+				"text-decoration-thickness: from-font",
+				"text-decoration-thickness: 0.1em",
+				"text-decoration-thickness: thick",
+			},
+			expected: []string{
+				"text-decoration-thickness: from-font",
+				"text-decoration-thickness: 0.1em", "",
+			},
+		},
+		{
+			in: []string{
+				"text-emphasis: filled dot red",
+				"text-emphasis: red open sesame", `text-emphasis: "x" #fff`,
+				"text-emphasis: none", "text-emphasis: filled red open",
+			},
+			expected: []string{
+				"text-emphasis: filled dot red",
+				"text-emphasis: red open sesame", `text-emphasis: "x" #fff`,
+				"text-emphasis: none", "",
+			},
+		},
+		{
+			in: []string{
+				"text-emphasis-position: over right",
+				"text-emphasis-position: left under",
+				"text-emphasis-position: left",
+			},
+			expected: []string{
+				"text-emphasis-position: over right",
+				"text-emphasis-position: left under", "",
+			},
+		},
+		{
+			in: []string{
+				"text-emphasis-style: open", "text-emphasis-style: dot filled",
+				"text-emphasis-style: dot circle",
+			},
+			expected: []string{
+				"text-emphasis-style: open", "text-emphasis-style: dot filled",
+				"",
+			},
+		},
 		{
 			in:       []string{"text-indent: 30%;", "text-indent: initial"},
 			expected: []string{"text-indent: 30%", "text-indent: initial"},
@@ -1068,6 +1526,46 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"text-transform: uppercase;"},
 			expected: []string{"text-transform: uppercase"},
 		},
+		{
+			in: []string{
+				"text-underline-offset: auto",
+				"text-underline-offset: calc(1em - 2px)",
+				"text-underline-offset: from-font",
+			},
+			expected: []string{
+				"text-underline-offset: auto",
+				"text-underline-offset: calc(1em - 2px)", "",
+			},
+		},
+		{
+			in: []string{
+				"text-underline-position: under left",
+				"text-underline-position: right from-font",
+				"text-underline-position: under from-font",
+			},
+			expected: []string{
+				"text-underline-position: under left",
+				"text-underline-position: right from-font", "",
+			},
+		},
+		{
+			in: []string{
+				"text-wrap: balance", "text-wrap: nowrap",
+				"text-wrap: wrap pretty", "text-wrap: wrap nowrap",
+			},
+			expected: []string{
+				"text-wrap: balance", "text-wrap: nowrap",
+				"text-wrap: wrap pretty", "",
+			},
+		},
+		{
+			in: []string{
+				"text-wrap-style: stable", "text-wrap-style: nowrap",
+			},
+			expected: []string{
+				"text-wrap-style: stable", "",
+			},
+		},
 		{
 			in:       []string{"top: 150px;"},
 			expected: []string{"top: 150px"},
@@ -1076,10 +1574,19 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in: []string{
 				"transform: scaleY(1.5);",
 				"transform: perspective(20px);",
+				"transform: skew(30deg);",
+				"transform: skewX(20deg);",
+				"transform: skew(10deg, -0.5turn);",
+				"transform: skew(1px);",
+				"transform: skewY(10deg, 10deg);",
 			},
 			expected: []string{
 				"transform: scaleY(1.5)",
 				"transform: perspective(20px)",
+				"transform: skew(30deg)",
+				"transform: skewX(20deg)",
+				"transform: skew(10deg, -0.5turn)",
+				"", "",
 			},
 		},
 		{
@@ -1142,6 +1649,15 @@ func TestDefaultStyleHandlers(t *testing.T) {
 			in:       []string{"white-space: normal;"},
 			expected: []string{"white-space: normal"},
 		},
+		{
+			in: []string{
+				"white-space-collapse: preserve-breaks",
+				"white-space-collapse: pre",
+			},
+			expected: []string{
+				"white-space-collapse: preserve-breaks", "",
+			},
+		},
 		{
 			in:       []string{"width: 130px;", "width: auto;"},
 			expected: []string{"width: 130px", "width: auto"},
@@ -1169,66 +1685,84 @@ func TestDefaultStyleHandlers(t *testing.T) {
 	}
 
 	allStyles := [...]string{
-		"nonexistentStyle", "align-content", "align-items",
-		"align-self", "all", "animation", "animation-delay",
-		"animation-direction", "animation-duration", "animation-fill-mode",
+		"nonexistentStyle", "align-content", "align-items", "align-self", "all",
+		"animation", "animation-delay", "animation-direction",
+		"animation-duration", "animation-fill-mode",
 		"animation-iteration-count", "animation-name", "animation-play-state",
-		"animation-timing-function", "backface-visibility", "background",
-		"background-attachment", "background-blend-mode", "background-clip",
-		"background-color", "background-image", "background-origin",
-		"background-position", "background-repeat", "background-size",
-		"border", "border-bottom", "border-bottom-color",
-		"border-bottom-left-radius", "border-bottom-right-radius",
-		"border-bottom-style", "border-bottom-width", "border-collapse",
-		"border-color", "border-image", "border-image-outset",
+		"animation-timing-function", "aspect-ratio", "backface-visibility",
+		"background", "background-attachment", "background-blend-mode",
+		"background-clip", "background-color", "background-image",
+		"background-origin", "background-position", "background-repeat",
+		"background-size", "block-size", "border", "border-block-end-color",
+		"border-block-end-width", "border-block-start", "border-block-style",
+		"border-bottom", "border-bottom-color", "border-bottom-left-radius",
+		"border-bottom-right-radius", "border-bottom-style",
+		"border-bottom-width", "border-collapse", "border-color",
+		"border-end-start-radius", "border-image", "border-image-outset",
 		"border-image-repeat", "border-image-slice", "border-image-source",
-		"border-image-width", "border-left", "border-left-color",
-		"border-left-style", "border-left-width", "border-radius",
-		"border-right", "border-right-color", "border-right-style",
-		"border-right-width", "border-spacing", "border-style", "border-top",
-		"border-top-color", "border-top-left-radius",
+		"border-image-width", "border-inline", "border-inline-color",
+		"border-inline-start-style", "border-inline-width", "border-left",
+		"border-left-color", "border-left-style", "border-left-width",
+		"border-radius", "border-right", "border-right-color",
+		"border-right-style", "border-right-width", "border-spacing",
+		"border-start-end-radius", "border-start-start-radius", "border-style",
+		"border-top", "border-top-color", "border-top-left-radius",
 		"border-top-right-radius", "border-top-style", "border-top-width",
 		"border-width", "bottom", "box-decoration-break", "box-shadow",
 		"box-sizing", "break-after", "break-before", "break-inside",
-		"caption-side", "caret-color", "clear", "clip", "color",
-		"column-count", "column-fill", "column-gap", "column-rule",
-		"column-rule-color", "column-rule-style", "column-rule-width",
-		"column-span", "column-width", "columns", "cursor", "direction",
-		"display", "empty-cells", "filter", "flex", "flex-basis",
-		"flex-direction", "flex-flow", "flex-grow", "flex-shrink",
-		"flex-wrap", "float", "font", "font-family", "font-kerning",
-		"font-language-override", "font-size", "font-size-adjust",
-		"font-stretch", "font-style", "font-synthesis", "font-variant",
-		"font-variant-caps", "font-variant-position", "font-weight", "grid",
-		"grid-area", "grid-auto-columns", "grid-auto-flow", "grid-auto-rows",
-		"grid-column", "grid-column-end", "grid-column-gap",
-		"grid-column-start", "grid-gap", "grid-row", "grid-row-end",
-		"grid-row-gap", "grid-row-start", "grid-template",
-		"grid-template-areas", "grid-template-columns", "grid-template-rows",
-		"hanging-punctuation", "height", "hyphens", "image-rendering",
-		"isolation", "justify-content", "left", "letter-spacing", "line-break",
-		"line-height", "list-style", "list-style-image", "list-style-position",
-		"list-style-type", "margin", "margin-bottom", "margin-left",
-		"margin-right", "margin-top", "max-height", "max-width", "min-height",
-		"min-width", "mix-blend-mode", "object-fit", "object-position",
-		"opacity", "order", "orphans", "outline", "outline-color",
-		"outline-offset", "outline-style", "outline-width", "overflow",
-		"overflow-wrap", "overflow-x", "overflow-y", "padding",
-		"padding-bottom", "padding-left", "padding-right", "padding-top",
+		"caption-side", "caret-color", "clear", "clip", "color", "column-count",
+		"column-fill", "column-gap", "column-rule", "column-rule-color",
+		"column-rule-style", "column-rule-width", "column-span", "column-width",
+		"columns", "cursor", "direction", "display", "empty-cells", "filter",
+		"flex", "flex-basis", "flex-direction", "flex-flow", "flex-grow",
+		"flex-shrink", "flex-wrap", "float", "font", "font-family",
+		"font-feature-settings", "font-kerning", "font-language-override",
+		"font-optical-sizing", "font-size", "font-size-adjust", "font-stretch",
+		"font-style", "font-synthesis", "font-variant",
+		"font-variant-alternates", "font-variant-caps",
+		"font-variant-east-asian", "font-variant-ligatures",
+		"font-variant-numeric", "font-variant-position",
+		"font-variation-settings", "font-weight", "gap", "grid", "grid-area",
+		"grid-auto-columns", "grid-auto-flow", "grid-auto-rows", "grid-column",
+		"grid-column-end", "grid-column-gap", "grid-column-start", "grid-gap",
+		"grid-row", "grid-row-end", "grid-row-gap", "grid-row-start",
+		"grid-template", "grid-template-areas", "grid-template-columns",
+		"grid-template-rows", "hanging-punctuation", "height",
+		"hyphenate-character", "hyphens", "image-rendering", "inline-size",
+		"inset", "inset-block-end", "inset-inline", "inset-inline-start",
+		"isolation", "justify-content", "justify-items", "justify-self", "left",
+		"letter-spacing", "line-break", "line-height", "list-style",
+		"list-style-image", "list-style-position", "list-style-type", "margin",
+		"margin-block-start", "margin-bottom", "margin-inline", "margin-left",
+		"margin-right", "margin-top", "max-height", "max-inline-size",
+		"max-width", "min-block-size", "min-height", "min-width",
+		"mix-blend-mode", "mso-ansi-font-size", "mso-border-alt",
+		"mso-color-alt", "mso-hide", "mso-line-height-rule",
+		"mso-margin-bottom-alt", "mso-margin-top-alt", "mso-padding-alt",
+		"mso-table-lspace", "mso-table-rspace", "mso-text-raise", "object-fit",
+		"object-position", "opacity", "order", "orphans", "outline",
+		"outline-color", "outline-offset", "outline-style", "outline-width",
+		"overflow", "overflow-inline", "overflow-wrap", "overflow-x",
+		"overflow-y", "padding", "padding-block-end", "padding-bottom",
+		"padding-inline", "padding-left", "padding-right", "padding-top",
 		"page-break-after", "page-break-before", "page-break-inside",
-		"perspective", "perspective-origin", "pointer-events", "position",
-		"quotes", "resize", "right", "scroll-behavior", "tab-size",
-		"table-layout", "text-align", "text-align-last",
-		"text-combine-upright", "text-decoration", "text-decoration-color",
-		"text-decoration-line", "text-decoration-style", "text-indent",
-		"text-justify", "text-orientation", "text-overflow", "text-shadow",
-		"text-transform", "top", "transform", "transform-origin",
+		"perspective", "perspective-origin", "place-content", "place-items",
+		"place-self", "pointer-events", "position", "quotes", "resize", "right",
+		"row-gap", "scroll-behavior", "tab-size", "table-layout", "text-align",
+		"text-align-last", "text-combine-upright", "text-decoration",
+		"text-decoration-color", "text-decoration-line",
+		"text-decoration-skip-ink", "text-decoration-style",
+		"text-decoration-thickness", "text-emphasis", "text-emphasis-position",
+		"text-emphasis-style", "text-indent", "text-justify",
+		"text-orientation", "text-overflow", "text-shadow", "text-transform",
+		"text-underline-offset", "text-underline-position", "text-wrap",
+		"text-wrap-style", "top", "transform", "transform-origin",
 		"transform-style", "transition", "transition-delay",
 		"transition-duration", "transition-property",
 		"transition-timing-function", "unicode-bidi", "user-select",
-		"vertical-align", "visibility", "white-space", "widows", "width",
-		"word-break", "word-spacing", "word-wrap", "writing-mode",
-		"z-index",
+		"vertical-align", "visibility", "white-space", "white-space-collapse",
+		"widows", "width", "word-break", "word-spacing", "word-wrap",
+		"writing-mode", "z-index",
 	}
 	p := NewPolicy().AllowStyles(allStyles[:]...).Globally()
 
@@ -1378,3 +1912,432 @@ func TestIssue171(t *testing.T) {
 		"color: red; text-align: center",
 		p.Sanitize("p", "color: red; text-align: center;   "))
 }
+
+func TestSanitizeWithReport(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("text-decoration").
+		MatchingEnum("underline", "line-through", "none").OnElements("span")
+	p.AllowStyles("color").
+		Matching(regexp.MustCompile("(?i)^#([0-9a-f]{3,4}|[0-9a-f]{6}|[0-9a-f]{8})$")).
+		Globally()
+	p.AllowStyles("background-origin").Globally()
+
+	tests := []struct {
+		element, in string
+		expected    string
+		rejected    []Rejection
+	}{
+		{
+			element:  "span",
+			in:       "text-decoration: underline; color: #f00",
+			expected: "text-decoration: underline; color: #f00",
+		},
+		{
+			element: "span",
+			in:      "Text-Decoration: blink; color: #f00ba; background-origin: invalidValue; margin: 0",
+			rejected: []Rejection{
+				{Property: "Text-Decoration", Value: "blink", Reason: RejectEnum},
+				{Property: "color", Value: "#f00ba", Reason: RejectRegexp},
+				{
+					Property: "background-origin", Value: "invalidValue",
+					Reason: RejectHandler,
+				},
+				{Property: "margin", Value: "0", Reason: RejectNoPolicy},
+			},
+		},
+		{
+			element:  "p",
+			in:       "text-decoration: underline; color: #fff",
+			expected: "color: #fff",
+			rejected: []Rejection{
+				{
+					Property: "text-decoration", Value: "underline",
+					Reason: RejectNoPolicy,
+				},
+			},
+		},
+		{
+			element: "p",
+			in:      `color: \100072ed`,
+			rejected: []Rejection{
+				{Property: "color", Value: `\100072ed`, Reason: RejectUnicode},
+			},
+		},
+		{
+			element: "p",
+			in:      "color; #fff",
+			rejected: []Rejection{
+				{Value: "color; #fff;", Reason: RejectParse},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		clean, rejected := p.SanitizeWithReport(tt.element, tt.in)
+		assert.Equal(t, tt.expected, clean, "test %v", i)
+		assert.Equal(t, tt.rejected, rejected, "test %v", i)
+		assert.Equal(t, p.Sanitize(tt.element, tt.in), clean, "test %v", i)
+	}
+
+	assert.Equal(t, "enum mismatch", RejectEnum.String())
+}
+
+func TestCompile(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("color").OnElements("span")
+	p.AllowStyles("text-align").
+		OnElementsMatching(regexp.MustCompile(`^my-`))
+	p.AllowStyles("font-size").
+		OnElementsMatching(regexp.MustCompile(`^my-el`))
+	c := p.Compile()
+
+	p.AllowStyles("margin").Globally()
+	p.AllowStyles("color").MatchingHandler(trueHandler).OnElements("span")
+
+	tests := []struct {
+		element, in, expected string
+	}{
+		{
+			element:  "span",
+			in:       "color: red; margin: 0",
+			expected: "color: red",
+		},
+		{
+			element:  "span",
+			in:       "color: invalidValue",
+			expected: "",
+		},
+		{
+			element:  "my-element",
+			in:       "text-align: center; font-size: 10px; color: red",
+			expected: "text-align: center; font-size: 10px",
+		},
+		{
+			element:  "my-other",
+			in:       "text-align: center; font-size: 10px",
+			expected: "text-align: center",
+		},
+		{
+			element: "p",
+			in:      "color: red",
+		},
+	}
+
+	for i, tt := range tests {
+		assert.Equal(t, tt.expected, c.Sanitize(tt.element, tt.in), "test %v", i)
+	}
+	assert.False(t, c.HasPolicies("p"))
+	assert.True(t, p.HasPolicies("p"))
+
+	clean, rejected := c.SanitizeWithReport("span", "color: red; margin: 0")
+	assert.Equal(t, "color: red", clean)
+	assert.Equal(t, []Rejection{
+		{Property: "margin", Value: "0", Reason: RejectNoPolicy},
+	}, rejected)
+}
+
+func TestCompile_cache(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("text-align").
+		OnElementsMatching(regexp.MustCompile(`^my-`))
+	p.DisallowStyles("text-align").MatchingEnum("left").
+		OnElementsMatching(regexp.MustCompile(`^my-el`))
+	require.Len(t, p.matchers, 1)
+	require.Len(t, p.denyMatchers, 1)
+
+	c := p.Compile()
+	assert.Empty(t, c.cache)
+	for range 2 {
+		assert.Equal(t, "text-align: center",
+			c.Sanitize("my-element", "text-align: center; text-align: left"))
+	}
+	assert.Len(t, c.cache, 1)
+	assert.Contains(t, c.cache, "my-element")
+
+	for i := range maxCachedElements + 1 {
+		c.Sanitize("my-"+strconv.Itoa(i), "text-align: center")
+	}
+	assert.Len(t, c.cache, maxCachedElements)
+	assert.Equal(t, "text-align: left",
+		c.Sanitize("my-other", "text-align: left"))
+}
+
+func TestCompile_concurrent(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("color").OnElements("span")
+	c := p.Compile()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 100 {
+				assert.Equal(t, "color: red", c.Sanitize("span", "color: red"))
+			}
+		})
+	}
+	for range 100 {
+		p.AllowStyles("margin").OnElements("span")
+	}
+	wg.Wait()
+}
+
+func TestDisallowStyles(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("font-size", "font-weight", "position").Globally()
+	p.AllowStyles("font-size").OnElements("td", "th")
+	p.AllowStyles("color").OnElementsMatching(regexp.MustCompile(`^h[1-6]$`))
+	p.DisallowStyles("font-size").OnElements("td")
+	p.DisallowStyles("position").MatchingEnum("fixed", "sticky").Globally()
+	p.DisallowStyles("color").Matching(regexp.MustCompile(`^red$`)).
+		OnElementsMatching(regexp.MustCompile(`^h1$`))
+
+	tests := []struct {
+		element, in, expected string
+	}{
+		{
+			element:  "td",
+			in:       "font-size: 10px; font-weight: bold",
+			expected: "font-weight: bold",
+		},
+		{
+			element:  "p",
+			in:       "font-size: 10px; font-weight: bold",
+			expected: "font-size: 10px; font-weight: bold",
+		},
+		{
+			element:  "p",
+			in:       "position: fixed; position: relative",
+			expected: "position: relative",
+		},
+		{
+			element:  "h1",
+			in:       "color: red; color: blue",
+			expected: "color: blue",
+		},
+		{
+			element:  "h2",
+			in:       "color: red",
+			expected: "color: red",
+		},
+	}
+
+	for i, tt := range tests {
+		assert.Equal(t, tt.expected, p.Sanitize(tt.element, tt.in), "test %v", i)
+	}
+
+	c := p.Compile()
+	for i, tt := range tests {
+		assert.Equal(t, tt.expected, c.Sanitize(tt.element, tt.in), "test %v", i)
+	}
+
+	_, rejected := p.SanitizeWithReport("td", "font-size: 10px")
+	assert.Equal(t, []Rejection{
+		{Property: "font-size", Value: "10px", Reason: RejectDenied},
+	}, rejected)
+}
+
+func TestCombinedValidators(t *testing.T) {
+	hex := regexp.MustCompile(`^#[0-9a-f]{3}$`)
+
+	t.Run("first", func(t *testing.T) {
+		p := NewPolicy()
+		p.AllowStyles("color").Matching(hex).MatchingEnum("red").Globally()
+		assert.Equal(t, "color: red", p.Sanitize("div", "color: red; color: #fff"))
+		require.ErrorIs(t, p.Err(), ErrIgnoredValidator)
+	})
+
+	t.Run("reported once", func(t *testing.T) {
+		p := NewPolicy()
+		b := p.AllowStyles("color").Matching(hex).MatchingEnum("red")
+		b.OnElements("span")
+		b.OnElementsMatching(regexp.MustCompile(`^h[1-6]$`))
+		b.Globally()
+		assert.Len(t, p.errs, 1)
+		require.ErrorIs(t, p.Err(), ErrIgnoredValidator)
+
+		b.MatchingEnum("blue").OnElements("p")
+		assert.Len(t, p.errs, 2)
+	})
+
+	t.Run("any", func(t *testing.T) {
+		p := NewPolicy()
+		p.AllowStyles("color").Matching(hex).MatchingEnum("red").MatchingAny().
+			Globally()
+		require.NoError(t, p.Err())
+		assert.Equal(t, "color: red; color: #fff",
+			p.Sanitize("div", "color: red; color: #fff; color: blue"))
+
+		_, rejected := p.SanitizeWithReport("div", "color: blue")
+		assert.Equal(t, []Rejection{
+			{Property: "color", Value: "blue", Reason: RejectRegexp},
+		}, rejected)
+	})
+
+	t.Run("all", func(t *testing.T) {
+		p := NewPolicy()
+		p.AllowStyles("color", "background-color").MatchingDefaultHandler().
+			Matching(regexp.MustCompile(`^#`)).MatchingAll().Globally()
+		require.NoError(t, p.Err())
+		assert.Equal(t, "color: #fff; background-color: #000",
+			p.Sanitize("div", "color: #fff; background-color: #000; color: red; color: #ff"))
+
+		_, rejected := p.SanitizeWithReport("div", "color: red; color: #ff")
+		assert.Equal(t, []Rejection{
+			{Property: "color", Value: "red", Reason: RejectRegexp},
+			{Property: "color", Value: "#ff", Reason: RejectHandler},
+		}, rejected)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		p := NewPolicy()
+		p.AllowStyles("color").MatchingEnum("red").MatchingEnum("blue").
+			Globally()
+		p.AllowStyles("color").MatchingHandler(trueHandler).
+			MatchingDefaultHandler().MatchingAll().OnElements("span")
+		p.AllowStyles("color").MatchingAny().MatchingAll().
+			OnElementsMatching(regexp.MustCompile(`^h`))
+		err := p.Err()
+		require.ErrorIs(t, err, ErrIgnoredValidator)
+		assert.Len(t, strings.Split(err.Error(), "\n"), 3)
+		assert.Equal(t, "color: blue", p.Sanitize("div", "color: red; color: blue"))
+	})
+}
+
+func TestMatchingTransform(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("color").
+		MatchingTransform(func(property, value string) (string, bool) {
+			return strings.ToLower(value), true
+		}).
+		MatchingDefaultHandler().Globally()
+	p.AllowStyles("font-family").
+		MatchingTransform(func(property, value string) (string, bool) {
+			if strings.Contains(strings.ToLower(value), "comic sans") {
+				return "sans-serif", true
+			}
+			return value, value != "Wingdings"
+		}).OnElements("p")
+
+	tests := []struct {
+		in, expected string
+	}{
+		{
+			in:       "color: #FFF; COLOR: Red",
+			expected: "color: #fff; COLOR: red",
+		},
+		{
+			in: "color: invalidValue",
+		},
+		{
+			in:       "font-family: 'Comic Sans MS'",
+			expected: "font-family: sans-serif",
+		},
+		{
+			in:       "font-family: Arial",
+			expected: "font-family: Arial",
+		},
+		{
+			in: "font-family: Wingdings",
+		},
+	}
+
+	for i, tt := range tests {
+		assert.Equal(t, tt.expected, p.Sanitize("p", tt.in), "test %v", i)
+	}
+
+	_, rejected := p.SanitizeWithReport("p",
+		"color: invalidValue; font-family: Wingdings")
+	assert.Equal(t, []Rejection{
+		{Property: "color", Value: "invalidValue", Reason: RejectHandler},
+		{Property: "font-family", Value: "Wingdings", Reason: RejectTransform},
+	}, rejected)
+
+	p = NewPolicy()
+	p.AllowStyles("color").
+		MatchingTransform(func(property, value string) (string, bool) {
+			return value, true
+		}).Globally()
+	p.AllowStyles("background-image").
+		MatchingTransform(func(property, value string) (string, bool) {
+			return "url(javascript:alert(1))", true
+		}).Globally()
+	clean, rejected := p.SanitizeWithReport("div",
+		"color: expression(alert(1)); background-image: none; color: red")
+	assert.Equal(t, "color: red", clean)
+	assert.Equal(t, []Rejection{
+		{Property: "color", Value: "expression(alert(1))", Reason: RejectHandler},
+		{Property: "background-image", Value: "none", Reason: RejectURL},
+	}, rejected)
+}
+
+func TestPolicy_Clone(t *testing.T) {
+	base := NewPolicy()
+	base.AllowStyles("color").OnElements("span")
+	base.AllowStyles("text-align").
+		OnElementsMatching(regexp.MustCompile(`^h[1-6]$`))
+	base.AllowStyles("margin").Globally()
+
+	tenant := base.Clone()
+	tenant.AllowStyles("font-size").OnElements("span")
+	tenant.AllowStyles("font-weight").
+		OnElementsMatching(regexp.MustCompile(`^h[1-6]$`))
+	tenant.AllowStyles("padding").Globally()
+	tenant.DisallowStyles("margin").OnElements("span")
+
+	in := "color: red; font-size: 1px; margin: 0; padding: 0"
+	assert.Equal(t, "color: red; margin: 0", base.Sanitize("span", in))
+	assert.Equal(t, "color: red; font-size: 1px; padding: 0",
+		tenant.Sanitize("span", in))
+
+	in = "text-align: left; font-weight: bold"
+	assert.Equal(t, "text-align: left", base.Sanitize("h1", in))
+	assert.Equal(t, "text-align: left; font-weight: bold",
+		tenant.Sanitize("h1", in))
+}
+
+func TestPolicy_Merge(t *testing.T) {
+	base := NewPolicy()
+	base.AllowStyles("color").MatchingEnum("red").OnElements("span")
+	base.AllowStyles("text-align").
+		OnElementsMatching(regexp.MustCompile(`^h[1-6]$`))
+
+	other := NewPolicy()
+	other.AllowStyles("color").MatchingEnum("blue").OnElements("span")
+	other.AllowStyles("font-weight").
+		OnElementsMatching(regexp.MustCompile(`^h[1-6]$`))
+	other.AllowStyles("margin").Globally()
+	other.DisallowStyles("margin").OnElements("span")
+
+	merged := base.Clone().Merge(other)
+	assert.Equal(t, "color: red; color: blue",
+		merged.Sanitize("span", "color: red; color: blue; color: green; margin: 0"))
+	assert.Equal(t, "text-align: left; font-weight: bold; margin: 0",
+		merged.Sanitize("h1", "text-align: left; font-weight: bold; margin: 0"))
+	assert.Len(t, merged.elsMatchingAndStyles, 1)
+
+	assert.Equal(t, "color: red",
+		base.Sanitize("span", "color: red; color: blue; margin: 0"))
+	assert.Equal(t, "color: blue",
+		other.Sanitize("span", "color: red; color: blue; margin: 0"))
+}
+
+func TestPolicy_Merge_matching(t *testing.T) {
+	a := NewPolicy()
+	a.AllowStyles("color").OnElements("h1")
+	a.AllowStyles("text-align").OnElementsMatching(regexp.MustCompile(`^p$`))
+
+	b := NewPolicy()
+	b.AllowStyles("font-weight").
+		OnElementsMatching(regexp.MustCompile(`^h[1-6]$`))
+	b.AllowStyles("margin").OnElements("p")
+
+	in := "color: red; font-weight: bold; text-align: left; margin: 0"
+	merged := a.Clone().Merge(b)
+	assert.Equal(t, "color: red; font-weight: bold", merged.Sanitize("h1", in))
+	assert.Equal(t, "font-weight: bold", merged.Sanitize("h2", in))
+	assert.Equal(t, "text-align: left; margin: 0", merged.Sanitize("p", in))
+
+	merged = b.Clone().Merge(a)
+	assert.Equal(t, "color: red; font-weight: bold", merged.Sanitize("h1", in))
+	assert.Equal(t, "text-align: left; margin: 0", merged.Sanitize("p", in))
+}
@@ -0,0 +1,119 @@
+package css
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PrefixMode describes how vendor prefixed properties are emitted.
+type PrefixMode int
+
+const (
+	// PrefixKeep emits vendor prefixed properties as they are. It's the default.
+	PrefixKeep PrefixMode = iota
+
+	// PrefixDrop removes all vendor prefixed properties.
+	PrefixDrop
+
+	// PrefixUnprefix emits vendor prefixed properties without prefix.
+	PrefixUnprefix
+)
+
+var prefixModeNames = map[PrefixMode]string{
+	PrefixKeep:     "keep",
+	PrefixDrop:     "drop",
+	PrefixUnprefix: "unprefix",
+}
+
+func (self PrefixMode) String() string {
+	if s, ok := prefixModeNames[self]; ok {
+		return s
+	}
+	return "PrefixMode(" + strconv.Itoa(int(self)) + ")"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (self PrefixMode) MarshalText() ([]byte, error) {
+	if s, ok := prefixModeNames[self]; ok {
+		return []byte(s), nil
+	}
+	return nil, fmt.Errorf("css: unknown prefix mode %d", int(self))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (self *PrefixMode) UnmarshalText(text []byte) error {
+	for mode, s := range prefixModeNames {
+		if s == string(text) {
+			*self = mode
+			return nil
+		}
+	}
+	return fmt.Errorf("css: unknown prefix mode %q", text)
+}
+
+var defaultVendorPrefixes = []string{
+	"-webkit-", "-moz-", "-ms-", "-o-", "-xv-", "-atsc-", "-wap-", "-khtml-",
+	"prince-", "-ah-", "-hp-", "-ro-", "-rim-", "-tc-",
+}
+
+// DefaultVendorPrefixes returns vendor prefixes recognised by a new policy.
+//
+// mso- isn't a vendor prefix here, because mso-* properties of Outlook aren't
+// prefixed standard properties. They have their own default handlers, like
+// mso-line-height-rule.
+func DefaultVendorPrefixes() []string {
+	return slices.Clone(defaultVendorPrefixes)
+}
+
+// VendorPrefixes sets vendor prefixes recognised by the policy and returns the
+// updated policy. A recognised prefix is removed from property name before
+// looking up its style policies, so allowing "transform" allows
+// "-webkit-transform" too. Without any prefixes, prefixed properties need their
+// own style policies.
+func (self *Policy) VendorPrefixes(prefixes ...string) *Policy {
+	self.prefixes = lowerStrings(prefixes)
+	return self
+}
+
+// PrefixOutput sets how vendor prefixed properties are emitted and returns the
+// updated policy.
+func (self *Policy) PrefixOutput(mode PrefixMode) *Policy {
+	self.prefixOutput = mode
+	return self
+}
+
+func lowerStrings(s []string) []string {
+	lower := make([]string, len(s))
+	for i := range s {
+		lower[i] = strings.ToLower(s[i])
+	}
+	return lower
+}
+
+func (self *Policy) vendorPrefixes() []string {
+	if self.prefixes == nil {
+		return defaultVendorPrefixes
+	}
+	return self.prefixes
+}
+
+// AllowPrefixes restricts vendor prefixes allowed for a nascent style policy
+// and returns the style policy. Without AllowPrefixes(...) all prefixes
+// recognised by the policy are allowed, and without arguments no prefixes are
+// allowed, like
+//
+//	p.AllowStyles("transform").AllowPrefixes("-webkit-").Globally()
+//
+// allows "transform" and "-webkit-transform", but not "-moz-transform".
+func (self *PolicyBuilder) AllowPrefixes(prefixes ...string) *PolicyBuilder {
+	self.prefixes = lowerStrings(prefixes)
+	return self
+}
+
+// allowPrefix returns true if the style policy allows vendor prefix.
+func (self *stylePolicy) allowPrefix(prefix string) bool {
+	return prefix == "" || self.prefixes == nil ||
+		slices.Contains(self.prefixes, prefix)
+}
@@ -0,0 +1,128 @@
+package css
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVendorPrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		policyFn func(p *Policy)
+		in       string
+		expected string
+	}{
+		{
+			name: "default",
+			policyFn: func(p *Policy) {
+				p.AllowStyles("transform", "line-height").Globally()
+			},
+			in:       "-webkit-transform: none; -xv-transform: none; mso-line-height-rule: exactly",
+			expected: "-webkit-transform: none; -xv-transform: none",
+		},
+		{
+			name: "mso",
+			policyFn: func(p *Policy) {
+				p.AllowStyles("mso-line-height-rule", "line-height").Globally()
+			},
+			in:       "mso-line-height-rule: exactly; line-height: 10px; mso-line-height-rule: 10px",
+			expected: "mso-line-height-rule: exactly; line-height: 10px",
+		},
+		{
+			name: "VendorPrefixes",
+			policyFn: func(p *Policy) {
+				p.VendorPrefixes("-WebKit-", "-moz-")
+				p.AllowStyles("transform").Globally()
+			},
+			in:       "-webkit-transform: none; -moz-transform: none; -xv-transform: none",
+			expected: "-webkit-transform: none; -moz-transform: none",
+		},
+		{
+			name: "without prefixes",
+			policyFn: func(p *Policy) {
+				p.VendorPrefixes()
+				p.AllowStyles("transform").Globally()
+			},
+			in:       "transform: none; -webkit-transform: none",
+			expected: "transform: none",
+		},
+		{
+			name: "AllowPrefixes",
+			policyFn: func(p *Policy) {
+				p.AllowStyles("transform").AllowPrefixes("-webkit-").Globally()
+				p.AllowStyles("transition").AllowPrefixes().Globally()
+			},
+			in:       "transform: none; -webkit-transform: none; -moz-transform: none; -webkit-transition: none; transition: none",
+			expected: "transform: none; -webkit-transform: none; transition: none",
+		},
+		{
+			name: "PrefixDrop",
+			policyFn: func(p *Policy) {
+				p.PrefixOutput(PrefixDrop)
+				p.AllowStyles("transform").Globally()
+			},
+			in:       "-webkit-transform: none; transform: none",
+			expected: "transform: none",
+		},
+		{
+			name: "PrefixUnprefix",
+			policyFn: func(p *Policy) {
+				p.PrefixOutput(PrefixUnprefix)
+				p.AllowStyles("transform").Globally()
+			},
+			in:       "-WebKit-Transform: none",
+			expected: "Transform: none",
+		},
+		{
+			name: "PrefixUnprefix Kelvin sign",
+			policyFn: func(p *Policy) {
+				p.PrefixOutput(PrefixUnprefix)
+				p.AllowStyles("transform").Globally()
+			},
+			in:       "-web\u212ait-transform: none; -webkit-transform: none",
+			expected: "transform: none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPolicy()
+			tt.policyFn(p)
+			assert.Equal(t, tt.expected, p.Sanitize("div", tt.in))
+
+			b, err := json.Marshal(p)
+			require.NoError(t, err)
+			p2 := NewPolicy()
+			require.NoError(t, json.Unmarshal(b, p2))
+			assert.Equal(t, tt.expected, p2.Sanitize("div", tt.in))
+		})
+	}
+
+	p := NewPolicy()
+	p.AllowStyles("transform").AllowPrefixes("-webkit-").Globally()
+	_, rejected := p.SanitizeWithReport("div", "-moz-transform: none")
+	assert.Equal(t, []Rejection{
+		{Property: "-moz-transform", Value: "none", Reason: RejectPrefix},
+	}, rejected)
+}
+
+func TestVendorPrefixes_Merge(t *testing.T) {
+	a := NewPolicy().VendorPrefixes("-webkit-")
+	a.AllowStyles("transform").Globally()
+	b := NewPolicy().VendorPrefixes("-moz-").PrefixOutput(PrefixUnprefix)
+
+	merged := a.Clone().Merge(b)
+	assert.Equal(t, []string{"-webkit-", "-moz-"}, merged.prefixes)
+	assert.Equal(t, PrefixUnprefix, merged.prefixOutput)
+	assert.Equal(t, "transform: none; transform: none",
+		merged.Sanitize("div", "-webkit-transform: none; -moz-transform: none"))
+
+	merged = NewPolicy().Merge(NewPolicy())
+	assert.Nil(t, merged.prefixes)
+
+	merged = NewPolicy().PrefixOutput(PrefixDrop).Merge(b)
+	assert.Equal(t, PrefixDrop, merged.prefixOutput)
+}
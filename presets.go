@@ -0,0 +1,117 @@
+package css
+
+import (
+	"regexp"
+	"slices"
+)
+
+var (
+	typographyStyles = []string{
+		"color", "direction", "font", "font-family", "font-kerning", "font-size",
+		"font-stretch", "font-style", "font-variant", "font-variant-caps",
+		"font-variant-position", "font-weight", "hyphens", "letter-spacing",
+		"line-height", "overflow-wrap", "text-align", "text-align-last",
+		"text-decoration", "text-decoration-color", "text-decoration-line",
+		"text-decoration-style", "text-indent", "text-justify", "text-transform",
+		"vertical-align", "white-space", "word-break", "word-spacing",
+		"word-wrap",
+	}
+
+	ugcStyles = []string{
+		"background-color", "border", "border-bottom", "border-bottom-color",
+		"border-bottom-left-radius", "border-bottom-right-radius",
+		"border-bottom-style", "border-bottom-width", "border-collapse",
+		"border-color", "border-left", "border-left-color", "border-left-style",
+		"border-left-width", "border-radius", "border-right",
+		"border-right-color", "border-right-style", "border-right-width",
+		"border-spacing", "border-style", "border-top", "border-top-color",
+		"border-top-left-radius", "border-top-right-radius", "border-top-style",
+		"border-top-width", "border-width", "box-sizing", "caption-side",
+		"clear", "empty-cells", "float", "height", "list-style-position",
+		"list-style-type", "max-height", "max-width", "min-height", "min-width",
+		"padding", "padding-bottom", "padding-left", "padding-right",
+		"padding-top", "table-layout", "text-shadow", "width",
+	}
+
+	// ugcNonNegative are allowed by UGCPolicy with non-negative values only, so
+	// content can't be pulled over other content, or out of the page
+	ugcNonNegative = []string{
+		"letter-spacing", "margin", "margin-bottom", "margin-left",
+		"margin-right", "margin-top", "text-indent", "word-spacing",
+	}
+	nonNegative = regexp.MustCompile(`^[^-]*$`)
+
+	// ugcNonZero are allowed by UGCPolicy with font sizes, which aren't zero,
+	// and without math functions, which can compute zero
+	ugcNonZero = []string{"font", "font-size"}
+	nonZero    = regexp.MustCompile(
+		`^(?:[^\s\d./(][^\s/(]*|[\d.]*[1-9][^\s/(]*)` +
+			`(?:[\s/]+(?:[^\s\d./(][^\s/(]*|[\d.]*[1-9][^\s/(]*))*$`)
+
+	// opaqueColor is a color without alpha, which UGCPolicy allows as color of
+	// text, so text can't be transparent. Named colors are all opaque, except
+	// transparent.
+	opaqueColor = regexp.MustCompile(`^(?:#[\da-f]{3}|#[\da-f]{6}|` +
+		`[a-su-z][a-z-]*|t(?:an|eal|histle|omato|urquoise)|` +
+		`(?:rgb|hsl)a?\([^,/()]*,[^,/()]*,[^,/()]*\)|` +
+		`(?:rgb|hsl|hwb|lab|lch|oklab|oklch)\((?:[\d.%\s+-]|deg|g?rad|turn|none)*\))$`)
+
+	emailStyles = []string{
+		"background-color", "border", "border-bottom", "border-bottom-color",
+		"border-bottom-style", "border-bottom-width", "border-collapse",
+		"border-color", "border-left", "border-left-color", "border-left-style",
+		"border-left-width", "border-right", "border-right-color",
+		"border-right-style", "border-right-width", "border-spacing",
+		"border-style", "border-top", "border-top-color", "border-top-style",
+		"border-top-width", "border-width", "color", "direction", "display",
+		"font", "font-family", "font-size", "font-style", "font-variant",
+		"font-weight", "height", "letter-spacing", "line-height",
+		"list-style-type", "margin", "margin-bottom", "margin-left",
+		"margin-right", "margin-top", "max-width", "min-width",
+		"mso-line-height-rule", "mso-padding-alt", "mso-table-lspace",
+		"mso-table-rspace", "padding", "padding-bottom", "padding-left",
+		"padding-right", "padding-top", "table-layout", "text-align",
+		"text-decoration", "text-indent", "text-transform", "vertical-align",
+		"white-space", "width", "word-spacing",
+	}
+)
+
+// TypographyPolicy returns a new policy, which allows text styling with default
+// handlers on every element: fonts, colors, spacing, alignment and decoration
+// of text.
+//
+// It can be used directly with bluemonday:
+//
+//	p := bluemonday.UGCPolicy().WithStyleHandler(css.TypographyPolicy().Sanitize)
+func TypographyPolicy() *Policy {
+	return NewPolicy().AllowStyles(typographyStyles...).Globally()
+}
+
+// UGCPolicy returns a new policy, which allows safe rich text styling of user
+// generated content with default handlers on every element. In addition to
+// TypographyPolicy() it allows background colors, borders, margins, paddings,
+// sizes, floats, lists and tables, but no positioning, transforms, animations
+// or images. Margins, indents and spacing can't be negative, font sizes can't
+// be zero and text can't be transparent.
+func UGCPolicy() *Policy {
+	typography := slices.DeleteFunc(slices.Clone(typographyStyles),
+		func(property string) bool {
+			return property == "color" || slices.Contains(ugcNonNegative, property) ||
+				slices.Contains(ugcNonZero, property)
+		})
+
+	return NewPolicy().AllowStyles(typography...).Globally().
+		AllowStyles(ugcStyles...).Globally().
+		AllowStyles(ugcNonNegative...).MatchingDefaultHandler().
+		Matching(nonNegative).MatchingAll().Globally().
+		AllowStyles(ugcNonZero...).MatchingDefaultHandler().
+		Matching(nonZero).MatchingAll().Globally().
+		AllowStyles("color").MatchingDefaultHandler().
+		Matching(opaqueColor).MatchingAll().Globally()
+}
+
+// EmailPolicy returns a new policy, which allows properties rendered by major
+// mail clients, with default handlers on every element.
+func EmailPolicy() *Policy {
+	return NewPolicy().AllowStyles(emailStyles...).Globally()
+}
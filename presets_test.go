@@ -0,0 +1,83 @@
+package css
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresets(t *testing.T) {
+	for _, styles := range [...][]string{
+		typographyStyles, ugcStyles, ugcNonNegative, ugcNonZero,
+		emailStyles,
+	} {
+		for _, property := range styles {
+			assert.Contains(t, defaultStyleHandlers, property)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		p        *Policy
+		in       string
+		expected string
+	}{
+		{
+			name:     "TypographyPolicy",
+			p:        TypographyPolicy(),
+			in:       "color: red; font-size: 12px; margin: 0",
+			expected: "color: red; font-size: 12px",
+		},
+		{
+			name:     "UGCPolicy",
+			p:        UGCPolicy(),
+			in:       "color: red; margin: 0; position: fixed; animation: mymove 5s infinite",
+			expected: "color: red; margin: 0",
+		},
+		{
+			name:     "UGCPolicy negative margins",
+			p:        UGCPolicy(),
+			in:       "margin: 1px -10px; margin-top: -1em; margin-left: calc(0px - 10px); margin-bottom: 5px",
+			expected: "margin-bottom: 5px",
+		},
+		{
+			name:     "UGCPolicy negative spacing",
+			p:        UGCPolicy(),
+			in:       "text-indent: -9999px; letter-spacing: -1em; word-spacing: -1em; text-indent: 1em",
+			expected: "text-indent: 1em",
+		},
+		{
+			name:     "UGCPolicy zero font size",
+			p:        UGCPolicy(),
+			in:       "font-size: 0; font-size: 0.0em; font-size: calc(0px); font: 0 serif; font: italic 0/1 serif; font: bold 12px/1.5 serif",
+			expected: "font: bold 12px/1.5 serif",
+		},
+		{
+			name:     "UGCPolicy transparent colors",
+			p:        UGCPolicy(),
+			in:       "color: transparent; color: #0000; color: rgba(0, 0, 0, 0); color: rgb(0 0 0 / 0); color: hsl(0, 0%, 0%)",
+			expected: "color: hsl(0, 0%, 0%)",
+		},
+		{
+			name:     "UGCPolicy opaque colors",
+			p:        UGCPolicy(),
+			in:       "color: tomato; font-size: 1.5em; background-color: transparent",
+			expected: "color: tomato; font-size: 1.5em; background-color: transparent",
+		},
+		{
+			name:     "EmailPolicy",
+			p:        EmailPolicy(),
+			in:       "width: 100px; display: block; float: left",
+			expected: "width: 100px; display: block",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.p.Err())
+			sanitize := tt.p.Sanitize
+			assert.Equal(t, tt.expected, sanitize("div", tt.in))
+		})
+	}
+}
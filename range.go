@@ -0,0 +1,142 @@
+package css
+
+import (
+	"maps"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var numberUnit = regexp.MustCompile(
+	`^([+-]?(?:\d+\.?\d*|\.\d+)(?:e[+-]?\d+)?)([a-z%]*)$`)
+
+// defaultUnitFactors are sizes of units in px, according to CSS Values and
+// Units. Sizes of em and rem assume the default font size of browsers.
+var defaultUnitFactors = map[string]float64{
+	"px":  1,
+	"pt":  4.0 / 3,
+	"pc":  16,
+	"in":  96,
+	"cm":  96 / 2.54,
+	"mm":  96 / 25.4,
+	"q":   96 / 101.6,
+	"em":  16,
+	"rem": 16,
+}
+
+// DefaultUnitFactors returns sizes of units in px, which a new policy uses to
+// compare values in different units.
+func DefaultUnitFactors() map[string]float64 {
+	return maps.Clone(defaultUnitFactors)
+}
+
+// Range is a range of numeric values allowed by PolicyBuilder.MatchingRange.
+type Range struct {
+	// Min and Max are inclusive bounds in the first of Units. They are
+	// infinite for unbounded ranges.
+	Min, Max float64
+
+	// Units are allowed units of values. Values without unit are allowed if
+	// it's empty.
+	Units []string
+}
+
+// MatchingRange allows a range of numeric values to be applied to a nascent
+// style policy, and returns the style policy. Every space separated token of a
+// value must be a number with one of units, between min and max inclusive. Use
+// math.Inf for an unbounded side, like
+//
+//	p.AllowStyles("font-size").MatchingRange(8, 48, "px", "pt", "em", "rem").
+//	  OnElements("span")
+//	p.AllowStyles("width").MatchingRange(math.Inf(-1), 100, "%").Globally()
+//
+// Bounds are in the first of units. Values in other units are converted by
+// factors set by Policy.UnitFactors, and aren't allowed, if it isn't possible.
+// A zero without unit is allowed, if it's in range. Without units only numbers
+// without unit are allowed.
+func (self *PolicyBuilder) MatchingRange(min, max float64, units ...string,
+) *PolicyBuilder {
+	if self.valueRange != nil {
+		self.ignored("range [%g, %g] replaced by [%g, %g]",
+			self.valueRange.Min, self.valueRange.Max, min, max)
+	}
+
+	r := Range{Min: min, Max: max, Units: make([]string, len(units))}
+	for i, unit := range units {
+		r.Units[i] = strings.ToLower(unit)
+	}
+	self.valueRange = &r
+	return self
+}
+
+// UnitFactors sets sizes of units in px, which MatchingRange uses to compare
+// values in different units, and returns the updated policy. Sizes of other
+// units aren't changed. By default em and rem are 16px, like
+//
+//	p.UnitFactors(map[string]float64{"em": 14, "rem": 14})
+func (self *Policy) UnitFactors(factors map[string]float64) *Policy {
+	if self.unitFactors == nil {
+		self.unitFactors = DefaultUnitFactors()
+	}
+	for unit, factor := range factors {
+		self.unitFactors[strings.ToLower(unit)] = factor
+	}
+	return self
+}
+
+// factors returns sizes of units in px.
+func (self *Policy) factors() map[string]float64 {
+	if self.unitFactors == nil {
+		return defaultUnitFactors
+	}
+	return self.unitFactors
+}
+
+// contains returns true if every token of value is a number in the range.
+func (self *Range) contains(value string, factors map[string]float64) bool {
+	var base string
+	if len(self.Units) > 0 {
+		base = self.Units[0]
+	}
+
+	tokens := strings.Fields(value)
+	for _, token := range tokens {
+		n, ok := self.number(token, base, factors)
+		if !ok || n < self.Min || n > self.Max {
+			return false
+		}
+	}
+	return len(tokens) > 0
+}
+
+// number returns token as a number in base unit, or false if it isn't an
+// allowed number.
+func (self *Range) number(token, base string, factors map[string]float64,
+) (float64, bool) {
+	m := numberUnit.FindStringSubmatch(token)
+	if m == nil {
+		return 0, false
+	}
+
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || math.IsInf(n, 0) {
+		return 0, false
+	}
+
+	unit := m[2]
+	switch {
+	case unit == base:
+		return n, true
+	case unit == "" && n == 0:
+		return 0, true
+	case !stringInSlice(unit, self.Units):
+		return 0, false
+	}
+
+	from, to := factors[unit], factors[base]
+	if from <= 0 || to <= 0 {
+		return 0, false
+	}
+	return n * from / to, true
+}
@@ -0,0 +1,119 @@
+package css
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchingRange(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("font-size").MatchingRange(8, 48, "px", "pt", "em", "rem").
+		Globally()
+	p.AllowStyles("width").MatchingRange(math.Inf(-1), 100, "%").Globally()
+	p.AllowStyles("line-height").MatchingRange(1, 2).Globally()
+	p.AllowStyles("margin").MatchingRange(0, 10, "px").Globally()
+	require.NoError(t, p.Err())
+
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{in: "font-size: 8px", expected: "font-size: 8px"},
+		{in: "font-size: 48PX", expected: "font-size: 48PX"},
+		{in: "font-size: 7.5px"},
+		{in: "font-size: 49px"},
+		{in: "font-size: 36pt", expected: "font-size: 36pt"},
+		{in: "font-size: 37pt"},
+		{in: "font-size: 3em", expected: "font-size: 3em"},
+		{in: "font-size: 3.5rem"},
+		{in: "font-size: 1in"},
+		{in: "font-size: large"},
+		{in: "font-size: 10px 10px", expected: "font-size: 10px 10px"},
+		{in: "width: 100%", expected: "width: 100%"},
+		{in: "width: -1e3%", expected: "width: -1e3%"},
+		{in: "width: 101%"},
+		{in: "width: 0", expected: "width: 0"},
+		{in: "width: 10px"},
+		{in: "line-height: 1.5", expected: "line-height: 1.5"},
+		{in: "line-height: .5"},
+		{in: "line-height: 1.5px"},
+		{in: "margin: 0 4px", expected: "margin: 0 4px"},
+		{in: "margin: 0 -4px"},
+		{in: "margin: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.expected, p.Sanitize("div", tt.in))
+		})
+	}
+
+	_, rejected := p.SanitizeWithReport("div", "width: 101%")
+	assert.Equal(t, []Rejection{
+		{Property: "width", Value: "101%", Reason: RejectRange},
+	}, rejected)
+
+	e := p.Explain("div", "width", "101%")
+	require.Len(t, e.Steps, 1)
+	require.NotNil(t, e.Steps[0].Validator)
+	assert.Equal(t, ValidatorRange, e.Steps[0].Validator.Kind)
+	assert.Equal(t, &Range{Min: math.Inf(-1), Max: 100, Units: []string{"%"}},
+		e.Steps[0].Validator.Range)
+}
+
+func TestPolicy_UnitFactors(t *testing.T) {
+	p := NewPolicy().UnitFactors(map[string]float64{"EM": 10})
+	p.AllowStyles("font-size").MatchingRange(8, 48, "px", "em", "rem").
+		Globally()
+
+	assert.Equal(t, "font-size: 4em", p.Sanitize("div", "font-size: 4em"))
+	assert.Empty(t, p.Sanitize("div", "font-size: 4rem"))
+	assert.Equal(t, 16.0, DefaultUnitFactors()["rem"])
+
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	p2 := NewPolicy()
+	require.NoError(t, json.Unmarshal(b, p2))
+	assert.Equal(t, "font-size: 4em", p2.Sanitize("div", "font-size: 4em"))
+	assert.Empty(t, p2.Sanitize("div", "font-size: 4rem"))
+	assert.Empty(t, p2.Sanitize("div", "font-size: 5em"))
+}
+
+func TestMatchingRange_combined(t *testing.T) {
+	p := NewPolicy()
+	p.AllowStyles("width").MatchingRange(0, 100, "px").
+		MatchingDefaultHandler().Globally()
+	require.ErrorIs(t, p.Err(), ErrIgnoredValidator)
+
+	p = NewPolicy()
+	p.AllowStyles("width").MatchingRange(0, 100, "px").
+		MatchingEnum("auto").MatchingAny().Globally()
+	require.NoError(t, p.Err())
+	assert.Equal(t, "width: auto", p.Sanitize("div", "width: auto"))
+	assert.Equal(t, "width: 10px", p.Sanitize("div", "width: 10px"))
+	assert.Empty(t, p.Sanitize("div", "width: 200px"))
+
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"global": {"width": [{
+		"enum": ["auto"], "range": {"min": 0, "max": 100, "units": ["px"]}
+	}]}}`, string(b))
+}
+
+func TestUnitFactors_Merge(t *testing.T) {
+	p := NewPolicy().UnitFactors(map[string]float64{"em": 10}).
+		Merge(NewPolicy().UnitFactors(map[string]float64{"em": 20, "ex": 8}))
+	assert.InDelta(t, 10, p.factors()["em"], 0)
+	assert.InDelta(t, 8, p.factors()["ex"], 0)
+	assert.InDelta(t, 1, p.factors()["px"], 0)
+
+	p = NewPolicy().Merge(NewPolicy().UnitFactors(map[string]float64{"em": 20}))
+	assert.InDelta(t, 20, p.factors()["em"], 0)
+
+	p = NewPolicy().Merge(NewPolicy())
+	assert.Nil(t, p.unitFactors)
+}
@@ -0,0 +1,112 @@
+package css
+
+import "strconv"
+
+// RejectReason describes why a style declaration has been removed by the
+// policy.
+type RejectReason int
+
+const (
+	// RejectNoPolicy means there is no style policy for the property on given
+	// element.
+	RejectNoPolicy RejectReason = iota + 1
+
+	// RejectHandler means the handler of the style policy rejected the value.
+	RejectHandler
+
+	// RejectEnum means the value isn't in the list of allowed values.
+	RejectEnum
+
+	// RejectRegexp means the value doesn't match the regular expression.
+	RejectRegexp
+
+	// RejectParse means the style attribute can't be parsed. All declarations
+	// of the style attribute are removed in this case.
+	RejectParse
+
+	// RejectUnicode means the value contains an invalid unicode escape sequence.
+	RejectUnicode
+
+	// RejectDenied means the declaration matches a deny rule.
+	RejectDenied
+
+	// RejectTransform means the transformer of the style policy rejected the
+	// value.
+	RejectTransform
+
+	// RejectPrefix means the vendor prefix of the property isn't allowed.
+	RejectPrefix
+
+	// RejectImportant means the declaration has !important, which isn't
+	// allowed.
+	RejectImportant
+
+	// RejectLimit means the style attribute or the value is over a limit of the
+	// policy.
+	RejectLimit
+
+	// RejectRange means the value isn't a number in the range.
+	RejectRange
+
+	// RejectUnit means the value contains a length with a unit, which isn't
+	// allowed.
+	RejectUnit
+
+	// RejectVariable means the value has var(), which references a variable,
+	// which isn't allowed, or has no fallback and isn't the whole value.
+	RejectVariable
+
+	// RejectURL means the value has url(), which isn't allowed by the URL policy.
+	RejectURL
+)
+
+var rejectReasonNames = map[RejectReason]string{
+	RejectNoPolicy:  "no policy",
+	RejectHandler:   "handler rejected",
+	RejectEnum:      "enum mismatch",
+	RejectRegexp:    "regexp mismatch",
+	RejectParse:     "parse failure",
+	RejectUnicode:   "unicode decode failure",
+	RejectDenied:    "denied",
+	RejectTransform: "transform rejected",
+	RejectPrefix:    "prefix not allowed",
+	RejectImportant: "!important not allowed",
+	RejectLimit:     "limit exceeded",
+	RejectRange:     "out of range",
+	RejectUnit:      "unit not allowed",
+	RejectVariable:  "variable not allowed",
+	RejectURL:       "url not allowed",
+}
+
+func (self RejectReason) String() string {
+	if s, ok := rejectReasonNames[self]; ok {
+		return s
+	}
+	return "RejectReason(" + strconv.Itoa(int(self)) + ")"
+}
+
+// Rejection describes a single style declaration removed by the policy.
+type Rejection struct {
+	// Property is the original property name, as it is in the style attribute.
+	// It's empty for RejectParse.
+	Property string
+
+	// Value is the original value of the property. For RejectParse it's the
+	// whole style attribute.
+	Value string
+
+	// Reason is why the declaration has been removed.
+	Reason RejectReason
+}
+
+type report []Rejection
+
+func (self *report) reject(property, value string, reason RejectReason) {
+	if self != nil {
+		*self = append(*self, Rejection{
+			Property: property,
+			Value:    value,
+			Reason:   reason,
+		})
+	}
+}
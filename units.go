@@ -0,0 +1,145 @@
+package css
+
+import (
+	"regexp"
+	"slices"
+)
+
+var dimension = regexp.MustCompile(
+	`[+-]?(?:\d+\.?\d*|\.\d+)(?:e[+-]?\d+)?([a-z]+|%)`)
+
+// lengthUnits are units of lengths and percentages, according to CSS Values and
+// Units Level 4.
+var lengthUnits = []string{
+	"%",
+	// absolute
+	"cm", "mm", "q", "in", "pt", "pc", "px",
+	// font relative
+	"em", "rem", "ex", "rex", "cap", "rcap", "ch", "rch", "ic", "ric", "lh",
+	"rlh",
+	// viewport
+	"vw", "vh", "vi", "vb", "vmin", "vmax",
+	"svw", "svh", "svi", "svb", "svmin", "svmax",
+	"lvw", "lvh", "lvi", "lvb", "lvmin", "lvmax",
+	"dvw", "dvh", "dvi", "dvb", "dvmin", "dvmax",
+	// container query
+	"cqw", "cqh", "cqi", "cqb", "cqmin", "cqmax",
+}
+
+// LengthUnits returns all units of lengths and percentages, which AllowUnits
+// restricts.
+func LengthUnits() []string {
+	return slices.Clone(lengthUnits)
+}
+
+// AllowUnits restricts units of lengths in values to units and returns the
+// updated policy. Without AllowUnits(...) all units are allowed, and without
+// arguments only lengths without unit, like 0, are allowed. It applies to every
+// style policy of the policy, which has no units of its own, like
+//
+//	p.AllowUnits("px", "em", "rem", "%")
+//
+// forbids viewport units, like "100vw", in values of all properties. Only
+// lengths are restricted: units of other dimensions, like angles and times, and
+// percentages, which aren't lengths, like "hsl(120, 50%, 50%)", aren't.
+func (self *Policy) AllowUnits(units ...string) *Policy {
+	self.units = lowerStrings(units)
+	return self
+}
+
+// AllowUnits restricts units of lengths in values of a nascent style policy and
+// returns the style policy. It takes precedence over units of the policy, like
+//
+//	p.AllowStyles("width").AllowUnits("px", "%").Globally()
+func (self *PolicyBuilder) AllowUnits(units ...string) *PolicyBuilder {
+	self.units = lowerStrings(units)
+	return self
+}
+
+// pinUnits sets units of the policy to its style policies without units of
+// their own, or all units if the policy has no units, so they keep their units
+// after merging into another policy.
+func (self *Policy) pinUnits() {
+	units := self.units
+	if units == nil {
+		units = lengthUnits
+	}
+
+	pin := func(styles map[string][]stylePolicy) {
+		for _, spl := range styles {
+			for i := range spl {
+				if spl[i].units == nil {
+					spl[i].units = slices.Clone(units)
+				}
+			}
+		}
+	}
+	for _, styles := range self.elsAndStyles {
+		pin(styles)
+	}
+	for _, styles := range self.elsMatchingAndStyles {
+		pin(styles)
+	}
+	pin(self.globalStyles)
+}
+
+// allowUnits returns true if value has no lengths with units, which the style
+// policy doesn't allow. Only percentages can be something else than lengths,
+// so they're checked only at length positions, and percentages of colors, like
+// "hsl(120, 50%, 50%)", or of opacity aren't restricted.
+func (self *Policy) allowUnits(sp *stylePolicy, value string) bool {
+	units := sp.units
+	if units == nil {
+		units = self.units
+	}
+	if units == nil {
+		return true
+	}
+
+	var accepted bool
+	for _, loc := range dimension.FindAllStringSubmatchIndex(value, -1) {
+		if !isDimension(value, loc[0], loc[1]) {
+			continue
+		}
+		unit := value[loc[2]:loc[3]]
+		switch {
+		case !slices.Contains(lengthUnits, unit) || slices.Contains(units, unit):
+			continue
+		case unit != "%" || sp.handler == nil:
+			return false
+		case !accepted:
+			// it's rejected anyway, if the handler doesn't accept the value
+			if !sp.handler(value) {
+				return len(sp.enum) == 0 && sp.regexp == nil && sp.valueRange == nil
+			}
+			accepted = true
+		}
+		if isLengthPosition(sp.handler, value, loc[0], loc[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isLengthPosition returns true if value[start:end] is at a length position of
+// handler, which means the handler still accepts value with a length in place
+// of it. Percentages of other types, like percentages of colors, aren't
+// replaceable by a length.
+func isLengthPosition(handler func(string) bool, value string, start, end int,
+) bool {
+	return handler(value[:start] + "1px" + value[end:])
+}
+
+// isDimension returns true if value[start:end] is a whole token, not a part of
+// an identifier or a hex color, like "h1" or "#1em".
+func isDimension(value string, start, end int) bool {
+	if start > 0 && isNameChar(value[start-1]) {
+		return false
+	}
+	return end == len(value) || !isNameChar(value[end]) && value[end] != '('
+}
+
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '-' || c == '.' || c == '#' || c >= 0x80
+}
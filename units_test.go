@@ -0,0 +1,146 @@
+package css
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowUnits(t *testing.T) {
+	p := NewPolicy().AllowUnits("px", "EM", "%")
+	p.AllowStyles("width", "margin", "color", "transform", "background-image").
+		Globally()
+	p.AllowStyles("height").AllowUnits("vh").Globally()
+	p.AllowStyles("padding").AllowUnits().Globally()
+	require.NoError(t, p.Err())
+
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{in: "width: 10px", expected: "width: 10px"},
+		{in: "width: 1.5EM", expected: "width: 1.5EM"},
+		{in: "width: 100%", expected: "width: 100%"},
+		{in: "width: 100vw"},
+		{in: "width: 10rem"},
+		{in: "margin: 0 auto 1px -2em", expected: "margin: 0 auto 1px -2em"},
+		{in: "margin: 0 auto 1px 2vmin"},
+		{in: "color: #1ee", expected: "color: #1ee"},
+		{in: "transform: rotate(360)", expected: "transform: rotate(360)"},
+		{
+			in:       "background-image: url('https://example.com/10vw.png')",
+			expected: "background-image: url('https://example.com/10vw.png')",
+		},
+		{in: "height: 10vh", expected: "height: 10vh"},
+		{in: "height: 10px"},
+		{in: "padding: 0", expected: "padding: 0"},
+		{in: "padding: 1px"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.expected, p.Sanitize("div", tt.in))
+		})
+	}
+
+	_, rejected := p.SanitizeWithReport("div", "width: 100vw")
+	assert.Equal(t, []Rejection{
+		{Property: "width", Value: "100vw", Reason: RejectUnit},
+	}, rejected)
+
+	b, err := json.Marshal(p)
+	require.NoError(t, err)
+	p2 := NewPolicy()
+	require.NoError(t, json.Unmarshal(b, p2))
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, p2.Sanitize("div", tt.in), tt.in)
+	}
+}
+
+func TestLengthHandler_angles(t *testing.T) {
+	assert.True(t, LengthHandler("10px"))
+	for _, value := range []string{"10deg", "1rad", "1turn"} {
+		assert.False(t, LengthHandler(value), value)
+	}
+	assert.Empty(t, NewPolicy().AllowStyles("width").Globally().
+		Sanitize("div", "width: 90deg"))
+}
+
+func TestAllowUnits_lengthPositions(t *testing.T) {
+	p := NewPolicy().AllowUnits("px", "em")
+	p.AllowStyles("color", "opacity", "width", "border", "background-image",
+		"font").Globally()
+	require.NoError(t, p.Err())
+
+	tests := []struct {
+		in       string
+		expected bool
+	}{
+		{"color: hsl(120, 50%, 50%)", true},
+		{"color: rgb(255 0 0 / 50%)", true},
+		{"color: color-mix(in srgb, red 30%, blue)", true},
+		{"opacity: 50%", true},
+		{"border: 1px solid hsl(0 50% 50%)", true},
+		{"border: 1vw solid hsl(0 50% 50%)", false},
+		{"width: 50%", false},
+		{"width: calc(100% - 2em)", false},
+		{"width: calc(10px + 2em)", true},
+		{"background-image: linear-gradient(hsl(0 50% 50%), red 1em)", true},
+		{"background-image: linear-gradient(red 10%, blue)", false},
+		{"font: 12px/150% serif", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if tt.expected {
+				assert.Equal(t, tt.in, p.Sanitize("div", tt.in))
+			} else {
+				assert.Empty(t, p.Sanitize("div", tt.in))
+			}
+		})
+	}
+}
+
+func TestAllowUnits_Merge(t *testing.T) {
+	base := NewPolicy().AllowUnits("px")
+	base.AllowStyles("width").Globally()
+	other := NewPolicy()
+	other.AllowStyles("height").Globally()
+
+	merged := base.Clone().Merge(other)
+	assert.Equal(t, "height: 10vh", merged.Sanitize("div", "width: 10vh; height: 10vh"))
+	assert.Nil(t, other.globalStyles["height"][0].units)
+
+	merged = other.Clone().Merge(base)
+	assert.Equal(t, "width: 10px; height: 10vh",
+		merged.Sanitize("div", "width: 10vh; width: 10px; height: 10vh"))
+
+	merged.AllowStyles("margin").Globally()
+	assert.Equal(t, "margin: 1vw", merged.Sanitize("div", "margin: 1vw"))
+}
+
+func TestAllowUnits_work(t *testing.T) {
+	// every token was checked at length position by the whole handler
+	limits := DefaultLimits()
+	properties := []string{
+		"text-decoration", "animation", "transition", "grid-template-columns",
+		"background-position",
+	}
+	for _, property := range properties {
+		for _, token := range []string{"1vw", "1%", "1%,"} {
+			value := strings.TrimSpace(
+				strings.Repeat(token+" ", limits.MaxShorthandTokens))
+			style := strings.Repeat(property+": "+value+"; ",
+				limits.MaxStyleTokens/limits.MaxShorthandTokens)
+			p := NewPolicy().AllowUnits("px")
+			p.AllowStyles(property).Globally()
+			start := time.Now()
+			p.Sanitize("div", style)
+			assert.Less(t, time.Since(start), time.Second, property+": "+token)
+		}
+	}
+}
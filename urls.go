@@ -0,0 +1,174 @@
+package css
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	urlFunction = regexp.MustCompile(`(?i)url\(`)
+	urlValue    = regexp.MustCompile(
+		`(?i)url\(\s*(?:"([^"\\\n]*)"|'([^'\\\n]*)'|([^\s"'()\\]*))\s*\)`)
+)
+
+// urlSchemes are schemes of URLs, which default handlers accept in url(). A
+// relative URL has empty scheme.
+var urlSchemes = []string{"", "http", "https", "data"}
+
+// defaultURLPolicy allows absolute http and https URLs, when the policy has no
+// URL policy of its own.
+var defaultURLPolicy = URLPolicy{Schemes: []string{"http", "https"}}
+
+// URLPolicy is a declarative allowlist of URLs in url() values, like
+//
+//	css.URLPolicy{
+//	  Schemes: []string{"https", "data"},
+//	  Hosts:   []string{"example.com", "*.example.com"},
+//	}
+type URLPolicy struct {
+	// Schemes are allowed schemes, like "https". An empty scheme allows
+	// relative URLs and "data" allows data: URLs with MediaTypes.
+	Schemes []string `json:"schemes,omitempty"`
+
+	// Hosts are allowed hosts, like "example.com" or "*.example.com" for its
+	// subdomains. Any host is allowed if it's empty.
+	Hosts []string `json:"hosts,omitempty"`
+
+	// Paths are allowed prefixes of paths, like "/images/". Any path is allowed
+	// if it's empty.
+	Paths []string `json:"paths,omitempty"`
+
+	// MediaTypes are allowed media types of data: URLs, like "image/png". Any
+	// image type is allowed if it's empty.
+	MediaTypes []string `json:"media_types,omitempty"`
+}
+
+// Allow returns true if u is allowed by the URL policy.
+func (self *URLPolicy) Allow(u *url.URL) bool {
+	scheme := strings.ToLower(u.Scheme)
+	if !slices.Contains(self.Schemes, scheme) {
+		return false
+	} else if scheme == "data" {
+		return self.allowData(u.Opaque)
+	}
+
+	if len(self.Hosts) > 0 && !slices.ContainsFunc(self.Hosts,
+		func(host string) bool { return matchHost(host, u.Hostname()) }) {
+		return false
+	}
+
+	if len(self.Paths) == 0 {
+		return true
+	}
+	cleaned := cleanPath(u.Path)
+	return slices.ContainsFunc(self.Paths,
+		func(prefix string) bool { return strings.HasPrefix(cleaned, prefix) })
+}
+
+// cleanPath returns p with dot segments resolved, like browsers resolve them
+// before requesting the URL, so "/images/../a.png" can't pass as a path under
+// "/images/". Backslashes are treated as slashes, like browsers do.
+func cleanPath(p string) string {
+	if p == "" {
+		return p
+	}
+	p = strings.ReplaceAll(p, `\`, "/")
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// allowData returns true if data of a data: URL has allowed media type.
+func (self *URLPolicy) allowData(data string) bool {
+	mediaType, _, ok := strings.Cut(data, ",")
+	if !ok {
+		return false
+	}
+	mediaType, _ = strings.CutSuffix(mediaType, ";base64")
+	mediaType, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return false
+	} else if len(self.MediaTypes) == 0 {
+		return strings.HasPrefix(mediaType, "image/")
+	}
+	return stringInSlice(mediaType, self.MediaTypes)
+}
+
+func (self *URLPolicy) clone() *URLPolicy {
+	return &URLPolicy{
+		Schemes:    slices.Clone(self.Schemes),
+		Hosts:      slices.Clone(self.Hosts),
+		Paths:      slices.Clone(self.Paths),
+		MediaTypes: slices.Clone(self.MediaTypes),
+	}
+}
+
+// matchHost returns true if host matches pattern, like "example.com" or
+// "*.example.com".
+func matchHost(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(strings.ToLower(host), strings.ToLower(suffix))
+	}
+	return strings.EqualFold(pattern, host)
+}
+
+// AllowURLs sets the URL policy, which every url() in values must be allowed
+// by, and returns the updated policy. Schemes and hosts are case-insensitive.
+// By default only absolute http and https URLs are allowed.
+func (self *Policy) AllowURLs(urls URLPolicy) *Policy {
+	urls.Schemes = lowerStrings(urls.Schemes)
+	self.urls, self.urlValidator = &urls, nil
+	return self
+}
+
+// URLValidator sets a validator, which every url() in values must be allowed
+// by, and returns the updated policy. It replaces the URL policy set by
+// AllowURLs. Unlike AllowURLs, it can't be represented in JSON.
+func (self *Policy) URLValidator(fn func(u *url.URL) bool) *Policy {
+	self.urls, self.urlValidator = nil, fn
+	return self
+}
+
+// isURL returns true if value is a single url() with a URL of scheme from
+// urlSchemes, so values like "url(javascript:alert(1))" are never accepted by
+// default handlers, whatever the URL policy is.
+func isURL(value string) bool {
+	if !URLFunction.MatchString(value) {
+		return false
+	}
+	m := urlValue.FindStringSubmatch(value)
+	u, err := url.Parse(m[1] + m[2] + m[3])
+	return err == nil && slices.Contains(urlSchemes, strings.ToLower(u.Scheme))
+}
+
+// allowURLs returns true if every url() in value is allowed by the policy.
+func (self *Policy) allowURLs(value string) bool {
+	matches := urlValue.FindAllStringSubmatch(value, -1)
+	if len(matches) != len(urlFunction.FindAllStringIndex(value, -1)) {
+		return false
+	}
+
+	for _, m := range matches {
+		u, err := url.Parse(m[1] + m[2] + m[3])
+		if err != nil || !self.allowURL(u) {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *Policy) allowURL(u *url.URL) bool {
+	switch {
+	case self.urlValidator != nil:
+		return self.urlValidator(u)
+	case self.urls != nil:
+		return self.urls.Allow(u)
+	}
+	return defaultURLPolicy.Allow(u)
+}
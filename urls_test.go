@@ -0,0 +1,276 @@
+package css
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowURLs(t *testing.T) {
+	const png = "data:image/png;base64,iVBORw0KGgo="
+	tests := []struct {
+		name     string
+		policyFn func(p *Policy)
+		in       string
+		expected bool
+	}{
+		{
+			name: "default https",
+			in:   "background-image: url('https://example.com/a.png')",
+
+			expected: true,
+		},
+		{
+			name: "default relative",
+			in:   "background-image: url(a.png)",
+		},
+		{
+			name: "default data",
+			in:   "background-image: url(" + png + ")",
+		},
+		{
+			name: "default javascript",
+			in:   "background-image: url(javascript:alert(1))",
+		},
+		{
+			name: "escaped scheme",
+			in:   `background-image: url(java\73 cript:x)`,
+		},
+		{
+			name: "host",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes: []string{"HTTPS"},
+					Hosts:   []string{"example.com", "*.cdn.example.com"},
+				})
+			},
+			in:       "background: red url(https://img.cdn.example.com/a.png) no-repeat",
+			expected: true,
+		},
+		{
+			name: "host mismatch",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes: []string{"https"},
+					Hosts:   []string{"example.com"},
+				})
+			},
+			in: "background: url(https://tracker.com/a.png)",
+		},
+		{
+			name: "host case",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes: []string{"https"},
+					Hosts:   []string{"example.com"},
+				})
+			},
+			in:       "list-style-image: url(HTTPS://EXAMPLE.COM/a.png)",
+			expected: true,
+		},
+		{
+			name: "path",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes: []string{"https"},
+					Paths:   []string{"/Images/"},
+				})
+			},
+			in:       "border-image: url(https://example.com/Images/a.png) 30 round",
+			expected: true,
+		},
+		{
+			name: "path mismatch",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes: []string{"https"},
+					Paths:   []string{"/Images/"},
+				})
+			},
+			in: "border-image: url(https://example.com/images/a.png) 30 round",
+		},
+		{
+			name: "path dot segments",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes: []string{"https"},
+					Paths:   []string{"/images/"},
+				})
+			},
+			in: "background-image: url(https://example.com/images/../track.gif)",
+		},
+		{
+			name: "path encoded dot segments",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes: []string{"https"},
+					Paths:   []string{"/images/"},
+				})
+			},
+			in: "background-image: url(https://example.com/images/%2e%2e/track.gif)",
+		},
+		{
+			name: "relative path dot segments",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes: []string{""},
+					Paths:   []string{"/images/"},
+				})
+			},
+			in: `background-image: url("/images/%2e%2e/track.gif")`,
+		},
+		{
+			name: "path clean",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes: []string{"https"},
+					Paths:   []string{"/images/"},
+				})
+			},
+			in:       "background-image: url(https://example.com/images/./a/../b.gif)",
+			expected: true,
+		},
+		{
+			name: "relative",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{Schemes: []string{""}})
+			},
+			in:       `cursor: url("/cursors/a.cur") 4 12, pointer`,
+			expected: true,
+		},
+		{
+			name: "data",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{Schemes: []string{"data"}})
+			},
+			in:       "background-image: url(" + png + ")",
+			expected: true,
+		},
+		{
+			name: "data media type",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{
+					Schemes:    []string{"data"},
+					MediaTypes: []string{"image/gif"},
+				})
+			},
+			in: "background-image: url(" + png + ")",
+		},
+		{
+			name: "data html",
+			policyFn: func(p *Policy) {
+				p.AllowURLs(URLPolicy{Schemes: []string{"data"}})
+			},
+			in: "background-image: url('data:text/html,<script>')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPolicy()
+			if tt.policyFn != nil {
+				tt.policyFn(p)
+			}
+			p.AllowStyles("background", "background-image", "border-image",
+				"list-style-image", "cursor").Globally()
+
+			b, err := json.Marshal(p)
+			require.NoError(t, err)
+			p2 := NewPolicy()
+			require.NoError(t, json.Unmarshal(b, p2))
+
+			for _, p := range []*Policy{p, p2} {
+				if tt.expected {
+					assert.Equal(t, tt.in, p.Sanitize("div", tt.in))
+				} else {
+					assert.Empty(t, p.Sanitize("div", tt.in))
+				}
+			}
+		})
+	}
+}
+
+func TestPolicy_URLValidator(t *testing.T) {
+	var urls []string
+	p := NewPolicy().URLValidator(func(u *url.URL) bool {
+		urls = append(urls, u.String())
+		return u.Host != "tracker.com"
+	})
+	p.AllowStyles("background-image", "cursor").Globally()
+
+	assert.Equal(t, "background-image: url(https://Example.com/A.png)",
+		p.Sanitize("div", "background-image: url(https://Example.com/A.png)"))
+	assert.Equal(t, []string{"https://Example.com/A.png"}, urls)
+
+	_, rejected := p.SanitizeWithReport("div",
+		"cursor: url(https://example.com/a.cur), url(https://tracker.com/a.cur), auto")
+	require.Len(t, rejected, 1)
+	assert.Equal(t, RejectURL, rejected[0].Reason)
+
+	_, err := json.Marshal(p)
+	require.ErrorIs(t, err, ErrNotSerializable)
+}
+
+func TestURLPolicy_Allow_paths(t *testing.T) {
+	urls := URLPolicy{Schemes: []string{"https"}, Paths: []string{"/images/"}}
+	for in, expected := range map[string]bool{
+		"https://example.com/images/a.gif":        true,
+		"https://example.com/images/a/../b.gif":   true,
+		"https://example.com/images/../track.gif": false,
+		"https://example.com/images/%2e%2e/a.gif": false,
+		`https://example.com/images/..\track.gif`: false,
+		"https://example.com/images":              false,
+	} {
+		u, err := url.Parse(in)
+		require.NoError(t, err)
+		assert.Equal(t, expected, urls.Allow(u), in)
+	}
+}
+
+func TestImageHandler_schemes(t *testing.T) {
+	p := NewPolicy().URLValidator(func(u *url.URL) bool { return true })
+	p.AllowStyles("background-image", "cursor").Globally()
+
+	for _, in := range []string{
+		`background-image: url("javascript:alert(1)")`,
+		"background-image: url(javascript:foo)",
+		"background-image: url(vbscript:foo)",
+		"cursor: url('javascript:foo'), auto",
+	} {
+		assert.Empty(t, p.Sanitize("div", in), in)
+	}
+
+	for _, in := range []string{
+		"background-image: url(https://example.com/a.png)",
+		"background-image: url(/a.png)",
+		"cursor: url(a.cur), auto",
+	} {
+		assert.Equal(t, in, p.Sanitize("div", in))
+	}
+}
+
+func TestAllowURLs_Merge(t *testing.T) {
+	const in = "background-image: url(/a.png)"
+	other := NewPolicy().AllowURLs(URLPolicy{Schemes: []string{""}})
+	p := NewPolicy()
+	p.AllowStyles("background-image").Globally()
+	assert.Empty(t, p.Sanitize("div", in))
+
+	p.Merge(other)
+	assert.Equal(t, in, p.Sanitize("div", in))
+	other.urls.Schemes[0] = "https"
+	assert.Equal(t, in, p.Sanitize("div", in))
+
+	p = NewPolicy().AllowURLs(URLPolicy{Schemes: []string{"https"}})
+	p.AllowStyles("background-image").Globally()
+	p.Merge(NewPolicy().AllowURLs(URLPolicy{Schemes: []string{""}}))
+	assert.Empty(t, p.Sanitize("div", in))
+
+	p = NewPolicy()
+	p.AllowStyles("background-image").Globally()
+	p.Merge(NewPolicy().URLValidator(func(u *url.URL) bool { return true }))
+	assert.Equal(t, in, p.Sanitize("div", in))
+}